@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"time"
+)
+
+const (
+	// adaptiveWeight is the weight given to each latency passed to Observe
+	// when updating the moving average.
+	adaptiveWeight = 0.25
+	// adaptiveMinScale is the smallest multiplier Adaptive will apply to a
+	// delay.
+	adaptiveMinScale = 0.5
+	// adaptiveMaxScale is the largest multiplier Adaptive will apply to a
+	// delay.
+	adaptiveMaxScale = 2
+)
+
+// Observe reports the latency of an attempt. When Adaptive is enabled, the
+// moving average of the observed latencies is compared against TargetLatency
+// and used to scale future delays, slow attempts increase the delay while fast
+// attempts decrease it.
+//
+// The scale is limited to between half and double the computed delay, and the
+// result is still clamped between Min and Max.
+func (b *Backoff) Observe(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+
+	if !b.observed {
+		b.latency = float64(latency)
+		b.observed = true
+	} else {
+		b.latency = adaptiveWeight*float64(latency) + (1-adaptiveWeight)*b.latency
+	}
+
+	// Update the delay computed by Next, so Duration reflects the new scale.
+	if b.Adaptive && b.hasNext {
+		b.next = b.delay(b.n)
+	}
+}
+
+// adaptiveScale returns the multiplier to apply to the computed delay.
+func (b *Backoff) adaptiveScale() float64 {
+	if !b.Adaptive || !b.observed || b.TargetLatency <= 0 {
+		return 1
+	}
+
+	scale := b.latency / float64(b.TargetLatency)
+	if scale < adaptiveMinScale {
+		return adaptiveMinScale
+	}
+	if scale > adaptiveMaxScale {
+		return adaptiveMaxScale
+	}
+	return scale
+}
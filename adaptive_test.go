@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBackoff_Observe(t *testing.T) {
+	for i, tc := range []struct {
+		name     string
+		adaptive bool
+		latency  time.Duration
+		expect   time.Duration
+	}{
+		{
+			name:     "Disabled",
+			adaptive: false,
+			latency:  time.Second,
+			expect:   2 * time.Second,
+		},
+		{
+			name:     "On target",
+			adaptive: true,
+			latency:  100 * time.Millisecond,
+			expect:   2 * time.Second,
+		},
+		{
+			name:     "Slow",
+			adaptive: true,
+			latency:  150 * time.Millisecond,
+			expect:   3 * time.Second,
+		},
+		{
+			name:     "Slow is limited",
+			adaptive: true,
+			latency:  10 * time.Second,
+			expect:   4 * time.Second,
+		},
+		{
+			name:     "Fast is limited",
+			adaptive: true,
+			latency:  time.Millisecond,
+			expect:   1 * time.Second,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBackoffWithMockTimer(0, 2, 500*time.Millisecond, 10*time.Second)
+			b.Adaptive = tc.adaptive
+			b.TargetLatency = 100 * time.Millisecond
+
+			ctx := context.Background()
+			b.Next(ctx)
+			b.Next(ctx)
+			b.Observe(tc.latency)
+
+			if d := b.Duration(); d != tc.expect {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, tc.expect, d)
+			}
+		})
+	}
+
+	t.Run("Uses a moving average", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 500*time.Millisecond, 10*time.Second)
+		b.Adaptive = true
+		b.TargetLatency = 100 * time.Millisecond
+
+		b.Next(context.Background())
+		b.Observe(100 * time.Millisecond)
+		// A single slow observation should only nudge the delay.
+		b.Observe(500 * time.Millisecond)
+
+		// avg = 0.25*500ms + 0.75*100ms = 200ms, scale = 2.
+		if d, expect := b.Duration(), 2*time.Second; d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+	})
+
+	t.Run("Still respects Max", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 3*time.Second)
+		b.Adaptive = true
+		b.TargetLatency = 100 * time.Millisecond
+
+		b.Next(context.Background())
+		b.Observe(time.Second)
+
+		if d := b.Duration(); d != b.Max {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Max, d)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// After waits for the backoff's next delay in a new goroutine, then calls fn
+// in that goroutine, like time.AfterFunc. The attempt is advanced as if Next
+// was called, so every call to After waits longer than the last one until the
+// backoff is reset. fn is not called if a limit such as MaxAttempts has been
+// reached or the context is cancelled before the delay has passed.
+//
+// The returned cancel func stops the pending call to fn, if fn has not been
+// called yet it never will be. Once cancel returns, the goroutine is no longer
+// waiting and the Timer has been stopped, so the Backoff can be used again.
+// cancel may be called multiple times, including from fn, and should always
+// be called once fn is no longer needed to release the context.
+//
+// The Backoff must not be used until fn has been called or cancel returns.
+func (b *Backoff) After(ctx context.Context, fn func()) (cancel func()) {
+	const (
+		pending = iota
+		fired
+		cancelled
+	)
+	var state atomic.Int32
+
+	ctx, stop := context.WithCancel(ctx)
+	waited := make(chan struct{})
+	go func() {
+		defer stop()
+		ok := b.Next(ctx)
+		close(waited)
+		if ok && state.CompareAndSwap(pending, fired) {
+			fn()
+		}
+	}()
+
+	return func() {
+		state.CompareAndSwap(pending, cancelled)
+		stop()
+		<-waited
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestBackoff_After(t *testing.T) {
+	t.Run("Calls fn after the delay", func(t *testing.T) {
+		b, timer := backoff.NewForTest()
+		b.InitialDelay = 5 * time.Millisecond
+
+		called := make(chan struct{})
+		cancel := b.After(context.Background(), func() { close(called) })
+		defer cancel()
+
+		select {
+		case <-called:
+		case <-time.After(time.Second):
+			t.Error("expected fn to be called")
+			return
+		}
+
+		if d := timer.Durations(); len(d) != 1 || d[0] != 5*time.Millisecond {
+			t.Errorf("expected durations to be \"%v\", but got \"%v\"", []time.Duration{5 * time.Millisecond}, d)
+		}
+		if b.Attempt() != 1 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 1, b.Attempt())
+		}
+	})
+
+	t.Run("Does not call fn once cancelled", func(t *testing.T) {
+		n := runtime.NumGoroutine()
+
+		b := backoff.New(0, 2, time.Hour, time.Hour)
+		b.InitialDelay = time.Hour
+
+		called := make(chan struct{})
+		cancel := b.After(context.Background(), func() { close(called) })
+		cancel()
+		cancel()
+
+		select {
+		case <-called:
+			t.Error("expected fn to not be called")
+		default:
+		}
+
+		waitForGoroutines(t, n)
+	})
+
+	t.Run("Does not call fn once the context is cancelled", func(t *testing.T) {
+		n := runtime.NumGoroutine()
+
+		b := backoff.New(0, 2, time.Hour, time.Hour)
+		b.InitialDelay = time.Hour
+
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		called := make(chan struct{})
+		cancel := b.After(ctx, func() { close(called) })
+		defer cancel()
+		cancelCtx()
+
+		waitForGoroutines(t, n)
+		select {
+		case <-called:
+			t.Error("expected fn to not be called")
+		default:
+		}
+	})
+
+	t.Run("Does not call fn once MaxAttempts is reached", func(t *testing.T) {
+		b, _ := backoff.NewForTest()
+		b.MaxAttempts = 1
+		b.NextN(1)
+
+		cancel := b.After(context.Background(), func() {
+			t.Error("expected fn to not be called")
+		})
+		cancel()
+	})
+
+	t.Run("Can be cancelled from fn", func(t *testing.T) {
+		b, _ := backoff.NewForTest()
+
+		done := make(chan struct{})
+		var cancel func()
+		started := make(chan struct{})
+		cancel = b.After(context.Background(), func() {
+			<-started
+			cancel()
+			close(done)
+		})
+		close(started)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("expected fn to return")
+		}
+	})
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// RetryAll runs every operation concurrently, retrying each one independently
+// using RetryResult with its own clone of template, see Backoff.Clone. It
+// waits for every operation to finish, the returned slice holds the value
+// returned by every operation at the same index, or the zero value of T if it
+// failed.
+//
+// If any operation failed, the errors are returned joined, each annotated
+// with the index of the operation that returned it. An operation failing does
+// not stop the others, cancel the context to stop them.
+func RetryAll[T any](ctx context.Context, template *Backoff, ops []func(context.Context) (T, error)) ([]T, error) {
+	return RetryAllN(ctx, template, 0, ops)
+}
+
+// RetryAllN behaves like RetryAll, but runs at most n operations at the same
+// time. If n is 0 or negative, the number of operations running at the same
+// time is not limited.
+func RetryAllN[T any](ctx context.Context, template *Backoff, n int, ops []func(context.Context) (T, error)) ([]T, error) {
+	var (
+		results = make([]T, len(ops))
+		errs    = make([]error, len(ops))
+		wg      sync.WaitGroup
+		sem     chan struct{}
+	)
+	if n > 0 {
+		sem = make(chan struct{}, n)
+	}
+	for i, op := range ops {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+		wg.Add(1)
+		go func(i int, op func(context.Context) (T, error), b *Backoff) {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			v, err := RetryResult(ctx, b, op, nil)
+			if err != nil {
+				errs[i] = fmt.Errorf("operation %d: %w", i, err)
+				return
+			}
+			results[i] = v
+		}(i, op, template.Clone())
+	}
+	wg.Wait()
+	return results, errors.Join(errs...)
+}
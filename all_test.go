@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestRetryAll(t *testing.T) {
+	t.Run("Retries every operation independently", func(t *testing.T) {
+		template := backoff.New(3, 2, time.Millisecond, time.Second)
+		template.DryRun = true
+
+		var calls [3]atomic.Int32
+		ops := make([]func(context.Context) (int, error), len(calls))
+		for i := range ops {
+			i := i
+			ops[i] = func(context.Context) (int, error) {
+				// Operation i succeeds on call i+1.
+				if calls[i].Add(1) <= int32(i) {
+					return 0, errRetry
+				}
+				return i * 10, nil
+			}
+		}
+
+		results, err := backoff.RetryAll(context.Background(), template, ops)
+		if err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		for i, v := range results {
+			if v != i*10 {
+				t.Errorf("Test #%d: expected result to be \"%d\", but got \"%d\"", i+1, i*10, v)
+			}
+			if c := calls[i].Load(); c != int32(i+1) {
+				t.Errorf("Test #%d: expected operation to be called \"%d\" times, but got \"%d\"", i+1, i+1, c)
+			}
+		}
+		if template.Attempt() != 0 {
+			t.Errorf("expected the template to not be used, but its attempt is \"%d\"", template.Attempt())
+		}
+	})
+
+	t.Run("Joins the errors", func(t *testing.T) {
+		template := backoff.New(2, 2, time.Millisecond, time.Second)
+		template.DryRun = true
+
+		errFatal := errors.New("fatal")
+		results, err := backoff.RetryAll(context.Background(), template, []func(context.Context) (string, error){
+			func(context.Context) (string, error) { return "ok", nil },
+			func(context.Context) (string, error) { return "", errRetry },
+			func(context.Context) (string, error) { return "", backoff.Permanent(errFatal) },
+		})
+		if !errors.Is(err, errRetry) || !errors.Is(err, backoff.ErrMaxAttempts) || !errors.Is(err, errFatal) {
+			t.Errorf("expected error to wrap every error, but got \"%v\"", err)
+		}
+		if results[0] != "ok" || results[1] != "" || results[2] != "" {
+			t.Errorf("expected results to be [ok  ], but got %v", results)
+		}
+	})
+
+	t.Run("Limits the number of running operations", func(t *testing.T) {
+		template := backoff.New(1, 2, time.Millisecond, time.Second)
+
+		var running, peak atomic.Int32
+		ops := make([]func(context.Context) (struct{}, error), 8)
+		for i := range ops {
+			ops[i] = func(context.Context) (struct{}, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				return struct{}{}, nil
+			}
+		}
+
+		if _, err := backoff.RetryAllN(context.Background(), template, 2, ops); err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		if p := peak.Load(); p > 2 {
+			t.Errorf("expected at most \"%d\" operations to run at the same time, but got \"%d\"", 2, p)
+		}
+	})
+}
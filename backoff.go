@@ -5,37 +5,254 @@ package backoff
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"math"
+	"sync/atomic"
 	"time"
 )
 
+// MaxSafeDuration is the largest delay that is computed as is, any delay
+// above it saturates to Max, or to the largest time.Duration if Max is 0. See
+// WouldOverflow to check whether a schedule reaches it.
+//
+// Delays are computed using float64, which cannot represent every int64 near
+// math.MaxInt64. math.MaxInt64 itself rounds up to 2^63 when converted to a
+// float64, which overflows when converted back to a time.Duration. Subtracting
+// 512, half of the spacing between float64 values at that magnitude, ensures
+// the limit rounds down to a value that converts back safely.
+const MaxSafeDuration = time.Duration(math.MaxInt64 - 512)
+
 // maxInt64 is used to avoid overflowing a time.Duration (int64) value.
-const maxInt64 = float64(math.MaxInt64 - 512)
+const maxInt64 = float64(MaxSafeDuration)
 
 // Backoff represents an exponential backoff.
 type Backoff struct {
 	// n is the current attempt and defaults to 0. The first attempt will not
-	// be delayed before it runs.
-	n uint
+	// be delayed before it runs, unless InitialDelay is set.
+	n uint64
+	// total is the number of attempts made since the Backoff was created or
+	// ResetAll was last called.
+	total uint64
 
 	// MaxAttempts is the max number of attempts that can occur. If set to 0
 	// the number of attempts will not be limited.
-	MaxAttempts uint
+	MaxAttempts uint64
 	// Factor is the factor at which Min will increase after each failed attempt.
+	// A Factor below 1 would decrease the delay after each failed attempt,
+	// but as delays are clamped to Min, every attempt is delayed by Min.
+	// Validate rejects a Factor that is not greater than 0, a Factor of 0 is
+	// not a constant schedule, see NewConstant for one. If an invalid
+	// Factor is used anyway, every attempt is delayed by Min.
 	Factor float64
+	// Base is multiplied with the delay before each attempt, which becomes
+	// Min * Base * Factor^attempt, the result is still clamped between Min and
+	// Max. This allows adjusting the starting magnitude of the delays
+	// separately from Min. Base is ignored if Strategy is set. If set to 0, a
+	// Base of 1 is used.
+	Base float64
 	// Min is the initial backoff time to wait after the first failed attempt.
 	Min time.Duration
-	// Max is the maximum time to wait before retrying.
+	// Max is the maximum time to wait before retrying. If set to 0, delays are
+	// only limited by the largest time.Duration. If Min and Max are both 0 and
+	// Strategy is nil, every attempt after the first one is retried
+	// immediately, without any Jitter, regardless of Factor.
 	Max time.Duration
+	// HardMax is the maximum time any single wait may take, including
+	// InitialDelay. Unlike Max, it is applied after everything else and does
+	// not change the schedule, the delays are computed and the limits are
+	// checked as if HardMax was not set, only the waits are shortened. This
+	// can be used to shorten delays in some environments, like CI, without
+	// changing the configured schedule. If set to 0, waits are not limited.
+	HardMax time.Duration
+	// NoMinClamp disables clamping delays to Min, so a Factor or Base below 1
+	// results in delays shorter than Min. Delays picked using Jitter are not
+	// clamped to Min either, so JitterFull may pick any delay down to zero
+	// unless JitterFloor is set. Delays are still clamped to Max.
+	NoMinClamp bool
+	// Strategy computes the delay before each attempt. If nil, Min is
+	// multiplied by Factor for every failed attempt.
+	Strategy Strategy
+	// MaxElapsed is the max amount of time since the first attempt after
+	// which no more attempts will start. Next gives up instead of waiting if
+	// the next attempt would start after MaxElapsed has passed, see Next for
+	// how it interacts with the other limits. If set to 0, the elapsed time
+	// will not be limited.
+	MaxElapsed time.Duration
+	// start is the time Next was first called since the Backoff was created
+	// or last reset.
+	start time.Time
+	// MinRemainingForRetry is the time that must be left before the deadline
+	// of the context passed to Next after waiting for a retry, usually the
+	// time the retried operation takes. If less time would be left, Next gives
+	// up immediately instead of starting a wait that cannot be followed by a
+	// successful attempt. The first attempt is never affected. If set to 0,
+	// Next waits until the deadline and gives up afterwards instead.
+	MinRemainingForRetry time.Duration
+	// MaxCappedWaits is the max number of consecutive attempts that can be
+	// delayed by Max before Next gives up, which usually means whatever is
+	// being retried is down. If set to 0, the number of consecutive attempts
+	// delayed by Max will not be limited.
+	MaxCappedWaits uint64
+	// cappedWaits is the number of consecutive attempts delayed by Max.
+	cappedWaits uint64
+	// OnSaturate is called by Next with the attempt about to be made once
+	// the delay before it first reaches Max, or the largest time.Duration if
+	// Max is 0, which is an early sign whatever is being retried is down. It
+	// is only called again after the delay drops below Max, for example after
+	// Reset. Ignored if nil.
+	OnSaturate func(attempt uint64)
+	// Budget limits the number of retries made by every Backoff sharing it.
+	// Next takes a token from the Budget before every retry, but not before
+	// the first attempt, and gives up instead of waiting if the Budget is
+	// exhausted. Clones share the Budget. If nil, retries are not limited.
+	Budget *Budget
+	// InitialDelay is the time to wait before the first attempt. It is not
+	// affected by Jitter, Round, Min or Max. Defaults to 0, which runs the first
+	// attempt immediately.
+	InitialDelay time.Duration
+	// DelayFirst shifts the schedule by one attempt, so the first attempt is
+	// delayed like the second one would be, with Jitter applied, and every
+	// attempt uses the delay of the attempt after it. This can be used to
+	// throttle every call, including the first. InitialDelay is ignored if
+	// DelayFirst is set, Validate rejects setting both.
+	DelayFirst bool
+
+	// Jitter controls how randomness is applied to the delay before each
+	// attempt. Jittered delays are still clamped between Min and Max.
+	Jitter JitterMode
+	// JitterFloor is the fraction, between 0 and 1, of the computed delay that
+	// Jitter is never allowed to go below. For example, a JitterFloor of 0.3
+	// with JitterFull picks a delay between 30% and 100% of the computed delay.
+	// The delay is still clamped to Min, so the larger of the two is used.
+	JitterFloor float64
+	// JitterAbsolute is the maximum amount of time randomly added to the delay
+	// before each attempt, regardless of the size of the delay. It is applied
+	// after Jitter and can be used on its own, the delay is still clamped
+	// between Min and Max. If zero, no time is added.
+	JitterAbsolute time.Duration
+	// FactorJitter randomizes Factor for every attempt, the factor used for
+	// each attempt is picked from [Factor-FactorJitter, Factor+FactorJitter].
+	// FactorJitter is ignored if Strategy is set.
+	FactorJitter float64
+	// next is the delay for the current attempt computed by the last call to
+	// Next, only valid if hasNext is true.
+	next    time.Duration
+	hasNext bool
+	// lastDelay is the delay picked by the last call to Next.
+	lastDelay time.Duration
+	// RecordHistory enables recording the delay before every attempt, see
+	// History. It is disabled by default as the history of a long-lived
+	// Backoff that is never reset grows without bounds.
+	RecordHistory bool
+	// history holds the delay before every attempt if RecordHistory is set.
+	history []time.Duration
+	// interrupted is true if the last call to Next was cancelled while
+	// waiting.
+	interrupted bool
+	// fired is true if the value sent by the Timer since it was last started
+	// was received, in which case its channel must not be drained again, and
+	// the Timer was not stopped since.
+	fired bool
+	// factors is the product of the factors picked for every attempt so far
+	// when FactorJitter is set, or zero if no factors have been picked yet.
+	factors float64
+	// Rand is the source of randomness used for Jitter. If nil, the top-level
+	// functions provided by math/rand/v2 are used, which do not contend on a
+	// lock when shared by many goroutines. See SeedRand for reproducible
+	// delays.
+	Rand Rand
+	// Round is the granularity delays are rounded to, after Jitter is applied
+	// but before they are clamped between Min and Max. If zero, delays are not
+	// rounded.
+	Round time.Duration
+
+	// ResetAfter is how long an attempt must have been succeeding since
+	// Succeeded was called for the backoff to be reset. This is used to avoid
+	// fully resetting the backoff when a connection flaps.
+	ResetAfter time.Duration
+	// succeededAt is the time Succeeded was first called since the last call
+	// to Next.
+	succeededAt time.Time
+	// succeeded is true if Succeeded was called after the last call to Next.
+	succeeded bool
+
+	// Adaptive enables scaling each delay by the latency reported to Observe
+	// relative to TargetLatency, see Observe for details.
+	Adaptive bool
+	// TargetLatency is the expected latency of an attempt when Adaptive is
+	// enabled. If zero, delays are not scaled.
+	TargetLatency time.Duration
+	// latency is the exponentially weighted moving average of the latencies
+	// passed to Observe.
+	latency float64
+	// observed is true once Observe has been called.
+	observed bool
+
+	// AttemptTimeout is the timeout of the context passed to every call to
+	// fn by RetryCtx and RetryResult. If zero, the context is only cancelled
+	// once the context passed to them is.
+	AttemptTimeout time.Duration
+	// SubtractWork makes Retry subtract the time taken by each call to fn
+	// from the following delay, so attempts start at a steady cadence
+	// regardless of how long they take, for example when polling. The
+	// reduced delay is not clamped to Min and is 0 if the call took longer
+	// than the delay.
+	SubtractWork bool
+
+	// Logger is used by Retry to log every retry at debug level, and a
+	// warning once it gives up. If nil, nothing is logged.
+	Logger *slog.Logger
+
+	// OnAttempt is called by Retry before every attempt with the current
+	// attempt, starting at 1. Ignored if nil.
+	OnAttempt func(attempt uint64)
+	// OnWait is called by Retry with the delay before waiting to retry.
+	// Ignored if nil.
+	OnWait func(d time.Duration)
+	// OnGiveUp is called by Retry with the number of attempts that were made
+	// and the error Retry is about to return, if it did not succeed. Ignored
+	// if nil.
+	OnGiveUp func(attempts uint64, err error)
+	// OnDrift is called by Next after every wait using the Timer with the
+	// requested delay and the time that actually passed according to Clock,
+	// excluding any time spent paused. A Timer firing late, for example
+	// because the scheduler is overloaded, results in a positive drift.
+	// Ignored if nil.
+	OnDrift func(requested, actual time.Duration)
 
 	// Timer is used for mocking in unit tests. For normal use, this should
 	// always be set to the result of `NewRealTimer()`, if you are creating
-	// a Backoff using the `New` function, this will be set by default.
+	// a Backoff using the `New` function, this will be set by default. If nil,
+	// it is created the first time it is needed using NewTimer, or
+	// NewRealTimer if NewTimer is nil.
 	Timer Timer
+	// NewTimer is used by Clone to create a Timer for the clone, so clones
+	// never share a Timer. This should be set when using a custom Timer with
+	// clones, like a mocked Timer in tests. If nil, see Clone.
+	NewTimer func() Timer
+	// Clock is the source of the current time, used to track MaxElapsed and
+	// ResetAfter. If nil, time.Now is used.
+	Clock Clock
+	// pause holds the *pauser used by Pause and Resume, it is created the
+	// first time it is needed.
+	pause atomic.Value
+	// nudge holds the *nudger used by Nudge, it is created the first time it
+	// is needed.
+	nudge atomic.Value
+	// active holds true while a sequence is in progress, from the first
+	// attempt until the Backoff is reset, so Group can tell whether the
+	// Backoff is idle while it may be used by another goroutine.
+	active atomic.Value
+	// DryRun disables waiting, Next and Sleep still compute every delay,
+	// increment the attempt and respect the limits and context, but return
+	// immediately instead of starting the Timer. This is useful to exercise
+	// retry logic in tests without a mocked Timer.
+	DryRun bool
 }
 
 // New returns a new Backoff instance.
-func New(maxAttempts uint, factor float64, min, max time.Duration) *Backoff {
+func New(maxAttempts uint64, factor float64, min, max time.Duration) *Backoff {
 	return &Backoff{
 		n: 0,
 
@@ -48,44 +265,442 @@ func New(maxAttempts uint, factor float64, min, max time.Duration) *Backoff {
 	}
 }
 
+// MustNew returns a new Backoff instance like New, but panics if the Backoff
+// is invalid. It is intended for package-level variables, where an error
+// cannot be handled.
+func MustNew(maxAttempts uint64, factor float64, min, max time.Duration) *Backoff {
+	b := New(maxAttempts, factor, min, max)
+	if err := b.Validate(); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// NewConstant returns a new Backoff that waits for d before every retry, using
+// a Factor of 1 with both Min and Max set to d.
+func NewConstant(maxAttempts uint64, d time.Duration) *Backoff {
+	return New(maxAttempts, 1, d, d)
+}
+
+// Validate returns an error if the Backoff is misconfigured.
+func (b *Backoff) Validate() error {
+	if b.Factor == 0 {
+		return fmt.Errorf("backoff: Factor must be greater than 0, use a Factor of 1 or NewConstant for a constant delay")
+	}
+	if math.IsNaN(b.Factor) || math.IsInf(b.Factor, 0) || b.Factor <= 0 {
+		return fmt.Errorf("backoff: Factor must be a finite number greater than 0, got %v", b.Factor)
+	}
+	if math.IsNaN(b.FactorJitter) || math.IsInf(b.FactorJitter, 0) || b.FactorJitter < 0 {
+		return fmt.Errorf("backoff: FactorJitter must be a finite, non-negative number, got %v", b.FactorJitter)
+	}
+	if math.IsNaN(b.Base) || math.IsInf(b.Base, 0) || b.Base < 0 {
+		return fmt.Errorf("backoff: Base must be a finite, non-negative number, got %v", b.Base)
+	}
+	if b.Min < 0 {
+		return fmt.Errorf("backoff: Min must not be negative, got %s", b.Min)
+	}
+	if b.Max < 0 {
+		return fmt.Errorf("backoff: Max must not be negative, got %s", b.Max)
+	}
+	if b.HardMax < 0 {
+		return fmt.Errorf("backoff: HardMax must not be negative, got %s", b.HardMax)
+	}
+	if b.Max != 0 && b.Min > b.Max {
+		return fmt.Errorf("backoff: Min (%s) must not be greater than Max (%s)", b.Min, b.Max)
+	}
+	if b.InitialDelay < 0 {
+		return fmt.Errorf("backoff: InitialDelay must not be negative, got %s", b.InitialDelay)
+	}
+	if b.DelayFirst && b.InitialDelay != 0 {
+		return fmt.Errorf("backoff: InitialDelay must not be set with DelayFirst")
+	}
+	if b.MaxElapsed < 0 {
+		return fmt.Errorf("backoff: MaxElapsed must not be negative, got %s", b.MaxElapsed)
+	}
+	if b.MinRemainingForRetry < 0 {
+		return fmt.Errorf("backoff: MinRemainingForRetry must not be negative, got %s", b.MinRemainingForRetry)
+	}
+	if b.AttemptTimeout < 0 {
+		return fmt.Errorf("backoff: AttemptTimeout must not be negative, got %s", b.AttemptTimeout)
+	}
+	if b.Round < 0 {
+		return fmt.Errorf("backoff: Round must not be negative, got %s", b.Round)
+	}
+	if math.IsNaN(b.JitterFloor) || b.JitterFloor < 0 || b.JitterFloor > 1 {
+		return fmt.Errorf("backoff: JitterFloor must be between 0 and 1, got %v", b.JitterFloor)
+	}
+	if b.JitterAbsolute < 0 {
+		return fmt.Errorf("backoff: JitterAbsolute must not be negative, got %s", b.JitterAbsolute)
+	}
+	if b.Jitter > JitterEqual {
+		return fmt.Errorf("backoff: unknown JitterMode %d", b.Jitter)
+	}
+	return nil
+}
+
+// Clone returns a copy of the Backoff with the same configuration, but with
+// its state reset as if it had just been created. The clone can be used
+// independently of the original Backoff.
+//
+// If NewTimer is set, the clone gets a new Timer returned by it. Otherwise if
+// the Backoff is using a Timer returned by NewRealTimer or NewTickerTimer, the
+// clone gets a new one, any other Timer is shared with the clone.
+func (b *Backoff) Clone() *Backoff {
+	c := *b
+	c.pause, c.nudge, c.active = atomic.Value{}, atomic.Value{}, atomic.Value{}
+	c.ResetAll()
+	if b.NewTimer != nil {
+		c.Timer = b.NewTimer()
+	} else {
+		switch b.Timer.(type) {
+		case *realTimer:
+			c.Timer = NewRealTimer()
+		case *tickerTimer:
+			c.Timer = NewTickerTimer()
+		}
+	}
+	return &c
+}
+
+// WithMaxAttempts returns a clone of the Backoff, see Clone, with MaxAttempts
+// set to n. The Backoff itself is not changed, so it can be used to tune a
+// shared Backoff for a single call:
+//
+//	err := shared.WithMaxAttempts(3).Retry(ctx, fn)
+func (b *Backoff) WithMaxAttempts(n uint64) *Backoff {
+	c := b.Clone()
+	c.MaxAttempts = n
+	return c
+}
+
+// WithFactor returns a clone of the Backoff, see Clone, with Factor set to
+// factor. The Backoff itself is not changed.
+func (b *Backoff) WithFactor(factor float64) *Backoff {
+	c := b.Clone()
+	c.Factor = factor
+	return c
+}
+
+// WithMin returns a clone of the Backoff, see Clone, with Min set to d. The
+// Backoff itself is not changed.
+func (b *Backoff) WithMin(d time.Duration) *Backoff {
+	c := b.Clone()
+	c.Min = d
+	return c
+}
+
+// WithMax returns a clone of the Backoff, see Clone, with Max set to d. The
+// Backoff itself is not changed.
+func (b *Backoff) WithMax(d time.Duration) *Backoff {
+	c := b.Clone()
+	c.Max = d
+	return c
+}
+
 // Attempt returns the current attempt.
-func (b *Backoff) Attempt() uint {
+func (b *Backoff) Attempt() uint64 {
 	return b.n
 }
 
+// RemainingAttempts returns the number of attempts that can still be made
+// before the MaxAttempts limit is reached. If MaxAttempts is 0, the number of
+// attempts is not limited and math.MaxUint64 is returned.
+func (b *Backoff) RemainingAttempts() uint64 {
+	if b.MaxAttempts == 0 {
+		return math.MaxUint64
+	}
+	if b.n >= b.MaxAttempts {
+		return 0
+	}
+	return b.MaxAttempts - b.n
+}
+
 // Duration returns the duration to wait for the current attempt. Useful for
 // logging when the next attempt will occur.
+//
+// Next computes the delay for the following attempt, including any Jitter,
+// before it returns, so after Next has been called the returned duration is
+// exactly what the next call to Next will wait. Before Next is called, the
+// returned duration does not include any Jitter.
 func (b *Backoff) Duration() time.Duration {
-	return b.duration(b.n)
+	if b.hasNext {
+		return b.next
+	}
+	return b.hardMax(b.duration(b.n))
+}
+
+// EstimateTotal returns the worst-case total time spent waiting between
+// attempts for a full sequence, starting from the first attempt. Useful for
+// picking MaxAttempts or setting an overall timeout.
+//
+// Jitter never increases a delay, while JitterAbsolute is added to every
+// delay in full, up to Max, so the returned duration is an upper bound for
+// jittered sequences. FactorJitter is not accounted for, Factor is used for
+// every attempt instead. Once delays reach Max or HardMax, every remaining
+// attempt is assumed to wait for as long, so large values of MaxAttempts do
+// not have to be iterated over.
+//
+// The returned bool is false if MaxAttempts is 0, as the sequence is
+// unbounded, or if the total is too large to be represented by a
+// time.Duration, in which case the largest time.Duration is returned.
+func (b *Backoff) EstimateTotal() (time.Duration, bool) {
+	if b.MaxAttempts == 0 {
+		return 0, false
+	}
+
+	// No delay is longer than ceiling, except the one before the first
+	// attempt. Without a Strategy, delays only ever move towards limit, so
+	// once it is reached every remaining delay is the same.
+	ceiling := b.hardMax(b.maxDelay())
+	limit := ceiling
+	if b.Strategy == nil {
+		limit = b.estimate(1 << 62)
+	}
+
+	var total time.Duration
+	for i := uint64(0); i < b.MaxAttempts; i++ {
+		d := b.estimate(i)
+		n := uint64(1)
+		if b.schedule(i) != 0 && (d == ceiling || d == limit) {
+			n = b.MaxAttempts - i
+		}
+		if d != 0 && n > uint64(math.MaxInt64-total)/uint64(d) {
+			return math.MaxInt64, false
+		}
+		total += d * time.Duration(n)
+		if n > 1 {
+			break
+		}
+	}
+	return total, true
+}
+
+// estimate returns the longest delay before the given attempt, accounting for
+// JitterAbsolute and HardMax, see EstimateTotal.
+func (b *Backoff) estimate(attempt uint64) time.Duration {
+	d := b.duration(attempt)
+	if b.JitterAbsolute > 0 && b.schedule(attempt) != 0 && d != 0 {
+		// d is clamped to Max, so this cannot overflow.
+		d += min(b.JitterAbsolute, b.maxDelay()-d)
+	}
+	return b.hardMax(d)
+}
+
+// Cap returns the longest time a single wait may take, accounting for Max,
+// HardMax and InitialDelay, which is not limited by Max. If Min and Max are
+// both 0 without a Strategy, only the first attempt may be delayed. The
+// returned bool is false if waits are not limited, when neither Max nor
+// HardMax is set.
+//
+// Jitter and Adaptive never result in a delay above Max, so they do not
+// affect the returned duration. Waits may still be shorter, for example if
+// the context passed to Next expires first.
+func (b *Backoff) Cap() (time.Duration, bool) {
+	var first time.Duration
+	if !b.DelayFirst {
+		first = max(b.InitialDelay, 0)
+	}
+
+	var c time.Duration
+	switch {
+	case b.immediate():
+		c = first
+	case b.Max > 0:
+		c = max(b.Max, first)
+	case b.HardMax > 0:
+		return b.HardMax, true
+	default:
+		return 0, false
+	}
+	return b.hardMax(c), true
+}
+
+// DelayAt returns the delay before the given attempt using the current
+// configuration, without Jitter, FactorJitter or the scaling applied by
+// Adaptive. Unlike Duration, it never changes or depends on the state of the
+// Backoff, so it can be called for any attempt in any order, for example to
+// display the schedule.
+func (b *Backoff) DelayAt(attempt uint64) time.Duration {
+	attempt = b.schedule(attempt)
+	if attempt == 0 {
+		return b.hardMax(max(b.InitialDelay, 0))
+	}
+	if b.Strategy != nil {
+		return b.hardMax(b.fromFloat(float64(b.Strategy.Delay(attempt))))
+	}
+	return b.hardMax(b.fromFloat(exponential(attempt, b.Factor, b.Min) * b.baseFactor()))
 }
 
 // duration returns the time.Duration to wait before running the given attempt.
-func (b *Backoff) duration(attempt uint) time.Duration {
-	// The first attempt should never have a delay.
+func (b *Backoff) duration(attempt uint64) time.Duration {
+	// The first attempt is only delayed by InitialDelay.
+	attempt = b.schedule(attempt)
 	if attempt == 0 {
+		return max(b.InitialDelay, 0)
+	}
+	if b.immediate() {
 		return 0
 	}
 
-	factor := math.Pow(b.Factor, float64(attempt))
-	durF := float64(b.Min) * factor
+	return b.fromFloat(b.base(attempt) * b.adaptiveScale())
+}
+
+// immediate returns true if Min and Max are both 0 without a Strategy, which
+// means attempts are retried immediately, regardless of Factor or Jitter.
+func (b *Backoff) immediate() bool {
+	return b.Strategy == nil && b.Min == 0 && b.Max == 0
+}
+
+// schedule returns the position of the given attempt in the schedule, which
+// is shifted by one if DelayFirst is set.
+func (b *Backoff) schedule(attempt uint64) uint64 {
+	if b.DelayFirst {
+		return attempt + 1
+	}
+	return attempt
+}
+
+// fromFloat converts a delay computed using floating-point math to a
+// duration, which is rounded and clamped between Min and Max.
+func (b *Backoff) fromFloat(durF float64) time.Duration {
+	// A misconfigured Backoff, for example with a NaN or negative Factor or a
+	// negative Min, results in a delay of Min instead of garbage.
+	if math.IsNaN(durF) || durF < 0 {
+		durF = 0
+	}
 	if durF > maxInt64 {
-		return b.Max
+		return b.maxDelay()
+	}
+
+	// Round to the nearest nanosecond instead of truncating, otherwise a
+	// product like 2.9999999999999996 caused by floating-point error would
+	// lose a whole nanosecond, which is significant when Min is tiny. Integer
+	// factors are exact as long as the delay is below 2^53ns (~104 days).
+	return b.clamp(b.round(time.Duration(math.Round(durF))))
+}
+
+// round rounds the given duration to a multiple of Round.
+func (b *Backoff) round(d time.Duration) time.Duration {
+	if b.Round <= 0 {
+		return d
+	}
+	return d.Round(b.Round)
+}
+
+// delay returns the time.Duration to wait before running the given attempt,
+// with Jitter and HardMax applied.
+func (b *Backoff) delay(attempt uint64) time.Duration {
+	d := b.duration(attempt)
+	if b.schedule(attempt) == 0 || d == 0 || (b.Jitter == JitterNone && b.JitterAbsolute <= 0) {
+		return b.hardMax(d)
 	}
+	return b.hardMax(b.clamp(b.round(b.jitterAbsolute(b.jitter(d)))))
+}
 
-	dur := time.Duration(durF)
-	if dur < b.Min {
-		return b.Min
+// base returns the delay before the given attempt, before it is clamped,
+// rounded or scaled.
+func (b *Backoff) base(attempt uint64) float64 {
+	if b.Strategy != nil {
+		return float64(b.Strategy.Delay(attempt))
 	}
-	if dur > b.Max {
-		return b.Max
+	// Factors are only picked for the current attempt.
+	if b.FactorJitter != 0 && b.factors != 0 && attempt == b.schedule(b.n) {
+		factors := b.factors
+		if b.DelayFirst {
+			// Factors are only picked after the first attempt.
+			factors *= b.Factor
+		}
+		return float64(b.Min) * b.baseFactor() * factors
 	}
-	return dur
+	return exponential(attempt, b.Factor, b.Min) * b.baseFactor()
+}
+
+// baseFactor returns Base, or 1 if Base is not set.
+func (b *Backoff) baseFactor() float64 {
+	if b.Base == 0 {
+		return 1
+	}
+	return b.Base
+}
+
+// exponential returns min * factor^attempt.
+func exponential(attempt uint64, factor float64, min time.Duration) float64 {
+	return float64(min) * math.Pow(factor, float64(attempt))
+}
+
+// WouldOverflow reports whether the delay before the given attempt, for a
+// Backoff using the given factor and min with a Base of 1, is too large to be
+// represented by a time.Duration. Once a schedule overflows, every delay is
+// Max, or the largest time.Duration if Max is 0. See Backoff.WouldOverflow to
+// account for the rest of the configuration, like Base.
+func WouldOverflow(attempt uint64, factor float64, min time.Duration) bool {
+	return exponential(attempt, factor, min) > maxInt64
+}
+
+// WouldOverflow reports whether the delay before the given attempt, using
+// the current configuration including Base, DelayFirst and Strategy, is too
+// large to be represented by a time.Duration, in which case the delay is Max,
+// or the largest time.Duration if Max is 0. Like DelayAt, FactorJitter and
+// Adaptive are not accounted for.
+func (b *Backoff) WouldOverflow(attempt uint64) bool {
+	attempt = b.schedule(attempt)
+	if attempt == 0 || b.immediate() {
+		return false
+	}
+	if b.Strategy != nil {
+		return float64(b.Strategy.Delay(attempt)) > maxInt64
+	}
+	return exponential(attempt, b.Factor, b.Min)*b.baseFactor() > maxInt64
+}
+
+// clamp restricts the given duration between Min and Max. If Min is greater
+// than Max, Max is used. The result is never negative.
+func (b *Backoff) clamp(d time.Duration) time.Duration {
+	if lo := max(b.Min, 0); d < lo && !b.NoMinClamp {
+		d = lo
+	}
+	if d < 0 {
+		d = 0
+	}
+	if hi := b.maxDelay(); d > hi {
+		d = hi
+	}
+	return d
+}
+
+// hardMax restricts the given duration to HardMax, if set.
+func (b *Backoff) hardMax(d time.Duration) time.Duration {
+	if b.HardMax > 0 && d > b.HardMax {
+		return b.HardMax
+	}
+	return d
+}
+
+// maxDelay returns Max, or the largest time.Duration if Max is not set.
+func (b *Backoff) maxDelay() time.Duration {
+	if b.Max <= 0 {
+		return math.MaxInt64
+	}
+	return b.Max
 }
 
 // Next increments the attempt, then waits for the duration of the attempt.
 // Once the duration has passed, Next returns true. Next will return false if
-// the attempt will exceed the MaxAttempts limit or if the given context has
+// the attempt will exceed the MaxAttempts, MaxElapsed or MaxCappedWaits
+// limits, if not enough time would be left before the context's deadline, see
+// MinRemainingForRetry, if the Budget is exhausted or if the given context has
 // been cancelled.
+// If the context is already cancelled, Next returns false without
+// incrementing the attempt or starting the Timer.
+//
+// If the context has a deadline, the wait never goes past it. If the delay,
+// including Jitter, would end at or after the deadline, Next only waits until
+// the deadline and returns false.
+//
+// The limits are checked in that order before waiting, so if multiple limits
+// are reached by the same attempt, NextErr reports the first one. MaxElapsed
+// is reached if the attempt would start after MaxElapsed has passed since the
+// first attempt, so Next never waits only to give up afterwards.
 //
 // This function was designed to be used as follows:
 //
@@ -93,38 +708,571 @@ func (b *Backoff) duration(attempt uint) time.Duration {
 //		// Do work, `continue` on soft-failure, `break` on success or non-retryable error.
 //	}
 func (b *Backoff) Next(ctx context.Context) bool {
-	if b.MaxAttempts != 0 && b.n >= b.MaxAttempts {
+	d, err := b.advance(ctx)
+	if err != nil {
 		return false
 	}
-	d := b.Duration()
-	b.n++
+	return b.wait(ctx, d)
+}
+
+// NextCapped behaves like Next, but waits for at most the given duration. The
+// attempt is incremented and the following delays are computed as if Next was
+// called, only this wait is capped. This can be used to temporarily shorten
+// delays without changing Max.
+func (b *Backoff) NextCapped(ctx context.Context, cap time.Duration) bool {
+	d, err := b.advance(ctx)
+	if err != nil {
+		return false
+	}
+	if d > cap {
+		d = max(cap, 0)
+		b.lastDelay = d
+		if b.RecordHistory {
+			b.history[len(b.history)-1] = d
+		}
+	}
+	return b.wait(ctx, d)
+}
+
+// NextUntil behaves like Next, but gives up once the given deadline has been
+// reached according to Clock. If the delay would end after the deadline, it
+// is shortened so the attempt starts at the deadline instead, which is the
+// last attempt.
+func (b *Backoff) NextUntil(ctx context.Context, deadline time.Time) bool {
+	remaining := deadline.Sub(b.now())
+	if remaining <= 0 {
+		return false
+	}
+	return b.NextCapped(ctx, remaining)
+}
 
-	// If the duration is zero, bypass the timer.
-	if d == 0 {
+// wait waits for the given duration or until the context is cancelled,
+// returning false if it was cancelled.
+//
+// The duration already has Jitter applied, if it would end at or after the
+// context's deadline it is clamped to the time remaining until the deadline
+// and wait returns false, as the attempt could not start in time. Jitter can
+// never push a wait past the deadline.
+func (b *Backoff) wait(ctx context.Context, d time.Duration) bool {
+	return b.waitProgress(ctx, d, 0, nil)
+}
+
+// waitProgress behaves like wait, but calls progress with the remaining time
+// every tick while waiting, starting with the time that will be waited for,
+// see NextWithProgress. If tick is not greater than 0 or progress is nil, it
+// behaves exactly like wait.
+func (b *Backoff) waitProgress(ctx context.Context, d, tick time.Duration, progress func(remaining time.Duration)) (ok bool) {
+	defer func() {
+		if !ok {
+			b.releaseTimer()
+		}
+	}()
+
+	if !b.awaitResume(ctx) {
+		return false
+	}
+
+	// If the duration is zero, bypass the timer and the deadline. This is the
+	// common case for the first attempt and must not allocate.
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	expires := false
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); d >= remaining {
+			d, expires = max(remaining, 0), true
+		}
+	}
+	if b.DryRun {
+		return ctx.Err() == nil && !expires
+	}
+	if d <= 0 {
+		// The deadline has passed, wait for the context to report it so its
+		// cause is set once wait returns.
+		<-ctx.Done()
+		b.interrupted = true
+		return false
+	}
+
+	pausing := b.pauser().pausing
+	n := b.nudger()
+	nudged := n.begin()
+	defer n.end()
+	start := b.now()
+	end := start.Add(d)
+	var ticks <-chan time.Time
+	if tick > 0 && progress != nil {
+		progress(d)
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	b.startTimer(d)
+	for {
 		select {
 		case <-ctx.Done():
+			b.stop()
 			return false
-		default:
+		case <-b.Timer.C():
+			b.fired = true
+			if expires {
+				// The timer may fire slightly before the context reports its
+				// deadline as exceeded, wait for it so its cause is set once
+				// wait returns.
+				<-ctx.Done()
+				b.interrupted = true
+				return false
+			}
+			if b.OnDrift != nil {
+				b.OnDrift(d, b.now().Sub(start))
+			}
+			return true
+		case <-nudged:
+			b.stopTimer()
 			return true
+		case <-pausing:
+			b.stopTimer()
+			paused := b.now()
+			remaining := max(end.Sub(paused), 0)
+			if !b.awaitResume(ctx) {
+				b.interrupted = true
+				return false
+			}
+			now := b.now()
+			start = start.Add(now.Sub(paused))
+			end = now.Add(remaining)
+			b.startTimer(remaining)
+		case <-ticks:
+			if remaining := end.Sub(b.now()); remaining > 0 {
+				progress(remaining)
+			}
 		}
 	}
+}
+
+// stop stops the timer after the wait was interrupted.
+func (b *Backoff) stop() {
+	b.stopTimer()
+	b.interrupted = true
+}
+
+// startTimer starts the timer for the given duration.
+func (b *Backoff) startTimer(d time.Duration) {
+	b.timer().Start(d)
+	b.fired = false
+}
+
+// releaseTimer stops the timer once the Backoff is done waiting, if it fired
+// and was not stopped since. A Timer returned by NewTickerTimer keeps running
+// after firing until it is stopped, other Timers are not affected.
+func (b *Backoff) releaseTimer() {
+	if b.fired {
+		b.Timer.Stop()
+		b.fired = false
+	}
+}
+
+// stopTimer stops the timer, draining its channel if needed.
+func (b *Backoff) stopTimer() {
+	// Stop the timer to release resources and prevent it from sending to a
+	// channel we are not listening to anymore.
+	if !b.Timer.Stop() && !b.fired {
+		// A value is in-flight, drain the channel as per the Timer contract
+		// to avoid leaking it. If the value was already received, draining
+		// again would block forever.
+		<-b.Timer.C()
+	}
+}
+
+// NextWithProgress behaves like Next, but calls progress with the remaining
+// time every tick while waiting, starting with the time that will be waited
+// for. This can be used to display a countdown until the next attempt.
+// progress is called from the same goroutine, so it should return quickly to
+// not delay the attempt.
+//
+// The wait is otherwise the same as the one of Next, including the deadline
+// of the context, Pause and Nudge. progress is not called while paused and
+// never after NextWithProgress returns. If tick is not greater than 0 or
+// progress is nil, NextWithProgress behaves like Next.
+func (b *Backoff) NextWithProgress(ctx context.Context, tick time.Duration, progress func(remaining time.Duration)) bool {
+	d, err := b.advance(ctx)
+	if err != nil {
+		return false
+	}
+	return b.waitProgress(ctx, d, tick, progress)
+}
 
-	b.Timer.Start(d)
-	select {
-	case <-ctx.Done():
-		// Stop the timer to release resources and prevent it from sending to a
-		// channel we are not listening to anymore.
-		if !b.Timer.Stop() {
-			// Drain the channel as per Go's documentation.
-			<-b.Timer.C()
+// timer returns the Timer, creating it using NewTimer or NewRealTimer if it is
+// nil, such as when the Backoff was not created using New.
+func (b *Backoff) timer() Timer {
+	if b.Timer == nil {
+		if b.NewTimer != nil {
+			b.Timer = b.NewTimer()
+		} else {
+			b.Timer = NewRealTimer()
 		}
+	}
+	return b.Timer
+}
+
+// Sleep behaves like Next, but waits without a context, so it cannot be
+// interrupted. It is intended for simple synchronous programs that do not have
+// a context, Next should be preferred in every other case.
+//
+//	for b.Sleep() {
+//		// Do work, `continue` on soft-failure, `break` on success or non-retryable error.
+//	}
+func (b *Backoff) Sleep() bool {
+	d, err := b.advance(context.Background())
+	if err != nil {
 		return false
-	case <-b.Timer.C():
-		return true
 	}
+	if d > 0 && !b.DryRun {
+		b.startTimer(d)
+		<-b.Timer.C()
+		b.fired = true
+	}
+	return true
+}
+
+// NextN skips ahead by n attempts without waiting, as if Next was called n
+// times. The attempt is not advanced past MaxAttempts.
+//
+// This is useful when resuming a sequence that was persisted, combined with
+// Duration it can be used to find the delay of any attempt. Skipping ahead
+// takes constant time, unless FactorJitter is set, in which case a factor is
+// picked for every skipped attempt.
+func (b *Backoff) NextN(n uint64) {
+	if n == 0 {
+		return
+	}
+	b.interrupted, b.lastDelay = false, 0
+	if b.FactorJitter == 0 {
+		n = min(n, b.RemainingAttempts(), math.MaxUint64-b.n)
+		b.n += n
+		b.total += n
+	} else {
+		for i := uint64(0); i < n && !b.exhausted(); i++ {
+			b.n++
+			b.total++
+			b.pickFactor()
+		}
+	}
+	b.active.Store(b.n != 0)
+	b.next, b.hasNext = b.delay(b.n), true
+}
+
+// SetAttempt sets the current attempt, as if the backoff had just made the
+// given number of attempts, so the next call to Next waits for the delay
+// before the following attempt. The attempt is not set past MaxAttempts.
+//
+// This is useful when resuming a sequence after a restart from a persisted
+// attempt, see Attempt. Unlike NextN, TotalAttempts is not affected.
+func (b *Backoff) SetAttempt(n uint64) {
+	if b.MaxAttempts != 0 && n > b.MaxAttempts {
+		n = b.MaxAttempts
+	}
+	b.n = n
+	b.active.Store(n != 0)
+	b.interrupted, b.lastDelay = false, 0
+	b.cappedWaits = 0
+	b.factors = 0
+	if b.FactorJitter != 0 && n != 0 {
+		// Continue the schedule from the given attempt, the factors picked
+		// from now on are multiplied with the ones that would have been
+		// picked on average.
+		b.factors = math.Pow(b.Factor, float64(n))
+	}
+	b.next, b.hasNext = 0, false
+}
+
+// SetFirstDelay sets the time to wait before the first attempt, which is 0 by
+// default, so the first attempt runs immediately. It sets InitialDelay and
+// clears DelayFirst, so the first attempt waits for exactly d, which is also
+// what Duration returns before Next is first called. A negative d is treated
+// as 0.
+//
+// For example, to space every attempt evenly, including the first one:
+//
+//	b := backoff.NewConstant(5, time.Second)
+//	b.SetFirstDelay(time.Second)
+func (b *Backoff) SetFirstDelay(d time.Duration) {
+	b.InitialDelay = max(d, 0)
+	b.DelayFirst = false
+}
+
+// advance increments the attempt unless a limit has been reached, returning
+// the duration to wait before the attempt, or an error describing the limit
+// that was reached. If the context is already cancelled, the cause of its
+// cancellation is returned before anything else, so the attempt is not
+// incremented and no Timer is started.
+func (b *Backoff) advance(ctx context.Context) (_ time.Duration, err error) {
+	defer func() {
+		if err != nil {
+			b.releaseTimer()
+		}
+	}()
+
+	if ctx.Err() != nil {
+		return 0, context.Cause(ctx)
+	}
+
+	now := b.now()
+	b.resetIfSucceeded(now)
+	b.succeeded = false
+	b.interrupted, b.lastDelay = false, 0
+
+	if b.exhausted() {
+		return 0, ErrMaxAttempts
+	}
+	if b.start.IsZero() {
+		b.start = now
+	}
+	d := b.next
+	if !b.hasNext {
+		d = b.delay(b.n)
+	}
+	if b.MaxElapsed > 0 && now.Sub(b.start)+d > b.MaxElapsed {
+		return 0, ErrMaxElapsed
+	}
+	if b.schedule(b.n) != 0 && b.duration(b.n) == b.maxDelay() {
+		b.cappedWaits++
+	} else {
+		b.cappedWaits = 0
+	}
+	if b.MaxCappedWaits != 0 && b.cappedWaits > b.MaxCappedWaits {
+		return 0, ErrMaxCappedWaits
+	}
+	if b.n != 0 && b.MinRemainingForRetry > 0 {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d+b.MinRemainingForRetry {
+			return 0, ErrDeadline
+		}
+	}
+	if b.n != 0 && b.Budget != nil && !b.Budget.Allow() {
+		return 0, ErrBudgetExhausted
+	}
+	b.lastDelay = d
+	if b.RecordHistory {
+		b.history = append(b.history, d)
+	}
+	if b.n == 0 {
+		b.active.Store(true)
+	}
+	b.n++
+	b.total++
+	b.pickFactor()
+	b.next, b.hasNext = b.delay(b.n), true
+	if b.cappedWaits == 1 && b.OnSaturate != nil {
+		b.OnSaturate(b.n)
+	}
+	return d, nil
+}
+
+// History returns the delay before every attempt since the Backoff was
+// created or last reset, including jitter, if RecordHistory is set. The first
+// value is the delay before the first attempt, which is InitialDelay. If a
+// wait was interrupted, the delay is recorded even though it was not waited
+// for in full.
+func (b *Backoff) History() []time.Duration {
+	if len(b.history) == 0 {
+		return nil
+	}
+	return append([]time.Duration(nil), b.history...)
+}
+
+// Status returns the state of the backoff as of the last call to Next. Useful
+// for reporting what was interrupted during a graceful shutdown.
+//
+// attempt is the current attempt, see Attempt. waiting is true if the context
+// was cancelled while Next was waiting, lastDelay is the delay Next waited or
+// was waiting for. If Next stopped because the MaxAttempts limit was
+// reached, waiting is false and lastDelay is 0.
+//
+// Status must not be called while Next is running.
+func (b *Backoff) Status() (attempt uint64, waiting bool, lastDelay time.Duration) {
+	return b.n, b.interrupted, b.lastDelay
+}
+
+// Snapshot is the state of a Backoff at a point in time, see Backoff.Snapshot.
+type Snapshot struct {
+	// Attempt is the current attempt, see Backoff.Attempt.
+	Attempt uint64
+	// NextDelay is the delay before the next attempt, see Backoff.Duration.
+	NextDelay time.Duration
+	// Elapsed is the time since the first attempt, or 0 if Next has not been
+	// called since the Backoff was created or last reset.
+	Elapsed time.Duration
+	// Saturated is true if the last delay reached Max, see OnSaturate.
+	Saturated bool
+	// RemainingAttempts is the number of attempts that can still be made,
+	// see Backoff.RemainingAttempts.
+	RemainingAttempts uint64
+}
+
+// Snapshot returns the state of the backoff, which is useful for exposing it
+// in metrics or a debug endpoint.
+//
+// Like Status, Snapshot must not be called while Next is running. To expose
+// the state of a Backoff used by another goroutine, take the snapshot from
+// that goroutine and share it instead.
+func (b *Backoff) Snapshot() Snapshot {
+	var elapsed time.Duration
+	if !b.start.IsZero() {
+		elapsed = b.now().Sub(b.start)
+	}
+	return Snapshot{
+		Attempt:           b.n,
+		NextDelay:         b.Duration(),
+		Elapsed:           elapsed,
+		Saturated:         b.cappedWaits > 0,
+		RemainingAttempts: b.RemainingAttempts(),
+	}
+}
+
+// exhausted returns true if the MaxAttempts limit has been reached.
+func (b *Backoff) exhausted() bool {
+	return b.MaxAttempts != 0 && b.n >= b.MaxAttempts
+}
+
+// idle returns true if no attempt has been made since the Backoff was
+// created or last reset. Unlike Attempt, it may be called while the Backoff
+// is used by another goroutine.
+func (b *Backoff) idle() bool {
+	active, _ := b.active.Load().(bool)
+	return !active
+}
+
+// NextCause behaves like Next, but additionally returns the cause of the
+// context's cancellation if that is the reason Next returned false. If the
+// MaxAttempts limit was reached instead, the returned error is nil.
+//
+// See context.Cause for details on the returned error.
+func (b *Backoff) NextCause(ctx context.Context) (bool, error) {
+	d, err := b.advance(ctx)
+	if err != nil {
+		return false, context.Cause(ctx)
+	}
+	if !b.wait(ctx, d) {
+		return false, waitErr(ctx)
+	}
+	return true, nil
+}
+
+// NextErr behaves like Next, but returns nil instead of true, or an error
+// describing why the backoff gave up instead of false. ErrMaxAttempts,
+// ErrMaxElapsed or ErrMaxCappedWaits is returned if a limit was reached,
+// ErrDeadline if not enough time would be left before the context's deadline,
+// see MinRemainingForRetry, or ErrBudgetExhausted if the Budget is exhausted,
+// otherwise the cause of the context's cancellation is returned.
+//
+//	for {
+//		if err := b.NextErr(ctx); err != nil {
+//			return err
+//		}
+//		// Do work, `continue` on soft-failure, `break` on success or non-retryable error.
+//	}
+func (b *Backoff) NextErr(ctx context.Context) error {
+	d, err := b.advance(ctx)
+	if err != nil {
+		return err
+	}
+	if !b.wait(ctx, d) {
+		return waitErr(ctx)
+	}
+	return nil
+}
+
+// waitErr returns the error describing why wait returned false, which is the
+// cause of the context's cancellation.
+func waitErr(ctx context.Context) error {
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+	// With DryRun set, a wait that would reach the context's deadline returns
+	// false without waiting for it, so the context is not done yet.
+	return context.DeadlineExceeded
+}
+
+// Succeeded records that the current attempt succeeded. If ResetAfter is 0,
+// the backoff is reset immediately, otherwise it will be reset by the next
+// call to Next if at least ResetAfter has passed since Succeeded was first
+// called for the attempt. The time spent waiting before the attempt does not
+// count, so an attempt that fails shortly after succeeding keeps backing off.
+//
+// This function was designed to be used by long-lived connections:
+//
+//	for b.Next(ctx) {
+//		conn, err := dial(ctx)
+//		if err != nil {
+//			continue
+//		}
+//		b.Succeeded()
+//		// Blocks until the connection drops.
+//		serve(conn)
+//	}
+func (b *Backoff) Succeeded() {
+	now := b.now()
+	if !b.succeeded {
+		b.succeededAt, b.succeeded = now, true
+	}
+	b.resetIfSucceeded(now)
+}
+
+// resetIfSucceeded resets the backoff if Succeeded was called at least
+// ResetAfter before now.
+func (b *Backoff) resetIfSucceeded(now time.Time) {
+	if !b.succeeded || now.Sub(b.succeededAt) < b.ResetAfter {
+		return
+	}
+	b.Reset()
 }
 
 // Reset resets the backoff back to 0, so it can be re-used.
+//
+// All state is cleared, including any delays picked using Jitter or
+// FactorJitter, the latencies passed to Observe and whether Succeeded was
+// called, leaving the Backoff as it was after being created. Only
+// TotalAttempts is kept, see ResetAll.
 func (b *Backoff) Reset() {
 	b.n = 0
+	b.active.Store(false)
+	b.next, b.hasNext = 0, false
+	b.lastDelay, b.interrupted = 0, false
+	b.cappedWaits = 0
+	b.factors = 0
+	b.succeededAt, b.succeeded = time.Time{}, false
+	b.start = time.Time{}
+	b.history = nil
+	b.latency, b.observed = 0, false
+}
+
+// TotalAttempts returns the number of attempts that were made since the
+// Backoff was created, unlike Attempt it is not affected by Reset. Useful for
+// reporting how often a long-lived connection was re-established.
+func (b *Backoff) TotalAttempts() uint64 {
+	return b.total
+}
+
+// ResetAll resets the backoff like Reset, and also resets TotalAttempts.
+func (b *Backoff) ResetAll() {
+	b.Reset()
+	b.total = 0
+}
+
+// Reconfigure updates Factor, Min and Max, then resets the backoff. If the
+// updated Backoff fails Validate, the error is returned and the Backoff is
+// left unchanged.
+//
+// This is useful when a server hints that it wants a different schedule, for
+// example through a Retry-After header.
+func (b *Backoff) Reconfigure(factor float64, min, max time.Duration) error {
+	oldFactor, oldMin, oldMax := b.Factor, b.Min, b.Max
+	b.Factor, b.Min, b.Max = factor, min, max
+	if err := b.Validate(); err != nil {
+		b.Factor, b.Min, b.Max = oldFactor, oldMin, oldMax
+		return err
+	}
+	b.Reset()
+	return nil
 }
@@ -5,7 +5,11 @@ package backoff_test
 
 import (
 	"context"
+	"errors"
 	"math"
+	"math/rand"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,13 +17,13 @@ import (
 )
 
 const (
-	_maxAttempts uint    = 3
+	_maxAttempts uint64  = 3
 	_factor      float64 = 2
 	_min                 = 1 * time.Second
 	_max                 = 5 * time.Second
 )
 
-func newBackoffWithMockTimer(maxAttempts uint, factor float64, min, max time.Duration) *backoff.Backoff {
+func newBackoffWithMockTimer(maxAttempts uint64, factor float64, min, max time.Duration) *backoff.Backoff {
 	b := backoff.New(maxAttempts, factor, min, max)
 	b.Timer = newMockTimer()
 	return b
@@ -88,6 +92,34 @@ func TestBackoff_Attempt(t *testing.T) {
 	}
 }
 
+func TestBackoff_RemainingAttempts(t *testing.T) {
+	t.Run("Unlimited", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, time.Millisecond, time.Second)
+		b.NextN(3)
+		if r := b.RemainingAttempts(); r != math.MaxUint64 {
+			t.Errorf("expected remaining attempts to be \"%d\", but got \"%d\"", uint64(math.MaxUint64), r)
+		}
+	})
+
+	t.Run("Counts down to zero", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, time.Millisecond, time.Second)
+		for _, expect := range []uint64{3, 2, 1, 0, 0} {
+			if r := b.RemainingAttempts(); r != expect {
+				t.Errorf("expected remaining attempts to be \"%d\", but got \"%d\"", expect, r)
+			}
+			b.Next(context.Background())
+		}
+	})
+
+	t.Run("Limits above 32 bits", func(t *testing.T) {
+		b := newBackoffWithMockTimer(math.MaxUint32+10, 2, time.Millisecond, time.Second)
+		b.NextN(2)
+		if r, expect := b.RemainingAttempts(), uint64(math.MaxUint32+8); r != expect {
+			t.Errorf("expected remaining attempts to be \"%d\", but got \"%d\"", expect, r)
+		}
+	})
+}
+
 func TestBackoff_Duration(t *testing.T) {
 	t.Run("Duration", func(t *testing.T) {
 		b := newBackoffWithMockTimer(0, 2, 500*time.Millisecond, 3*time.Second)
@@ -136,6 +168,19 @@ func TestBackoff_Duration(t *testing.T) {
 		}
 	})
 
+	t.Run("Duration is always Min when Factor is below 1", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0.5, 1*time.Second, 5*time.Second)
+
+		ctx := context.Background()
+		for i := 0; i < 10; i++ {
+			b.Next(ctx)
+			if duration := b.Duration(); duration != b.Min {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, b.Min, duration)
+				return
+			}
+		}
+	})
+
 	t.Run("Duration does not exceed Max", func(t *testing.T) {
 		b := newBackoffWithMockTimer(0, 2, 3*time.Second, 500*time.Millisecond)
 		if b == nil {
@@ -201,6 +246,63 @@ func TestBackoff_Next(t *testing.T) {
 		cancel()
 	})
 
+	t.Run("Does not start the timer when context is already cancelled", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(0, 2, 1*time.Second, 5*time.Second)
+		b.Timer = timer
+		b.NextN(2)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if b.Next(ctx) {
+			t.Error("expected Next to return false")
+		}
+		if err := b.NextErr(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", context.Canceled, err)
+		}
+
+		if len(timer.durations) != 0 {
+			t.Errorf("expected the timer to not be started, but it was started with %v", timer.durations)
+		}
+		if b.Attempt() != 2 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+		}
+	})
+
+	t.Run("Does not wait past the context deadline with jitter", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Hour, time.Hour)
+		b.Jitter = backoff.JitterFull
+		b.Rand = fixedRand(0.5)
+		b.NextN(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if err := b.NextErr(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected Next to return by the deadline, but it took %s", elapsed)
+		}
+		if _, waiting, _ := b.Status(); !waiting {
+			t.Error("expected the wait to be interrupted")
+		}
+	})
+
+	t.Run("Continues when the jittered delay ends before the context deadline", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Hour, time.Hour)
+		b.Jitter = backoff.JitterFull
+		b.NoMinClamp = true
+		b.Rand = fixedRand(0.000005)
+		b.NextN(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if !b.Next(ctx) {
+			t.Error("expected Next to return true")
+		}
+	})
+
 	t.Run("Aborts between attempts when context is cancelled", func(t *testing.T) {
 		// This test sets time parameters to test the other branch of Next.
 		// Next has two logic paths, one for when there is no duration and
@@ -249,7 +351,7 @@ func TestBackoff_Next(t *testing.T) {
 			return
 		}
 
-		var i uint
+		var i uint64
 		ctx := context.Background()
 		for b.Next(ctx) {
 			i++
@@ -268,7 +370,7 @@ func TestBackoff_Next(t *testing.T) {
 		}
 
 		var (
-			i            uint
+			i            uint64
 			lastDuration = b.Duration()
 		)
 		ctx := context.Background()
@@ -309,3 +411,2033 @@ func TestBackoff_Reset(t *testing.T) {
 		return
 	}
 }
+
+func TestBackoff_EstimateTotal(t *testing.T) {
+	t.Run("Unbounded", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+		if _, ok := b.EstimateTotal(); ok {
+			t.Error("expected EstimateTotal to return false when MaxAttempts is zero")
+		}
+	})
+
+	t.Run("Matches the time waited", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(4, 2, 1*time.Second, 5*time.Second)
+		b.Timer = timer
+
+		total, ok := b.EstimateTotal()
+		if !ok {
+			t.Fatal("expected EstimateTotal to return true when MaxAttempts is set")
+		}
+		if expect := 11 * time.Second; total != expect {
+			t.Errorf("expected total to be \"%s\", but got \"%s\"", expect, total)
+			return
+		}
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		var waited time.Duration
+		for _, d := range timer.durations {
+			waited += d
+		}
+		if waited != total {
+			t.Errorf("expected waited time to be \"%s\", but got \"%s\"", total, waited)
+		}
+	})
+
+	t.Run("Is an upper bound when jittered", func(t *testing.T) {
+		b := newBackoffWithMockTimer(4, 2, 1*time.Second, 5*time.Second)
+		b.Jitter = backoff.JitterFull
+
+		total, _ := b.EstimateTotal()
+		if expect := 11 * time.Second; total != expect {
+			t.Errorf("expected total to be \"%s\", but got \"%s\"", expect, total)
+		}
+	})
+
+	t.Run("Is an upper bound with JitterAbsolute", func(t *testing.T) {
+		for i, tc := range []struct {
+			max    time.Duration
+			expect time.Duration
+		}{
+			// 0s, 2s+5s, 4s+5s, 8s+5s
+			{max: 0, expect: 29 * time.Second},
+			// 0s, 2s+3s, 4s+1s, 5s
+			{max: 5 * time.Second, expect: 15 * time.Second},
+		} {
+			timer := &mockTimer{}
+			b := backoff.New(4, 2, 1*time.Second, tc.max)
+			b.Timer = timer
+			b.JitterAbsolute = 5 * time.Second
+			b.Rand = fixedRand(0.999)
+
+			total, _ := b.EstimateTotal()
+			if total != tc.expect {
+				t.Errorf("Test #%d: expected total to be \"%s\", but got \"%s\"", i+1, tc.expect, total)
+			}
+
+			ctx := context.Background()
+			for b.Next(ctx) {
+			}
+			var waited time.Duration
+			for _, d := range timer.durations {
+				waited += d
+			}
+			if waited > total {
+				t.Errorf("Test #%d: expected waited time \"%s\" to be at most \"%s\"", i+1, waited, total)
+			}
+		}
+	})
+
+	t.Run("Saturates instead of overflowing", func(t *testing.T) {
+		b := newBackoffWithMockTimer(math.MaxUint32, 2, 1*time.Second, time.Duration(math.MaxInt64))
+		total, ok := b.EstimateTotal()
+		if total != time.Duration(math.MaxInt64) {
+			t.Errorf("expected total to be \"%s\", but got \"%s\"", time.Duration(math.MaxInt64), total)
+		}
+		if ok {
+			t.Error("expected EstimateTotal to return false when the total overflows")
+		}
+	})
+
+	t.Run("Returns quickly for large MaxAttempts", func(t *testing.T) {
+		for i, tc := range []struct {
+			name   string
+			b      *backoff.Backoff
+			expect time.Duration
+		}{
+			{
+				name: "Max",
+				// 0s, 2s, 4s, then 5s for every other attempt.
+				b:      backoff.New(1<<30, 2, 1*time.Second, 5*time.Second),
+				expect: 6*time.Second + (1<<30-3)*5*time.Second,
+			},
+			{
+				name: "HardMax",
+				b: func() *backoff.Backoff {
+					b := backoff.New(1<<30, 2, 1*time.Second, 0)
+					b.HardMax = 3 * time.Second
+					return b
+				}(),
+				// 0s, 2s, then 3s for every other attempt.
+				expect: 2*time.Second + (1<<30-2)*3*time.Second,
+			},
+			{
+				name: "Min",
+				// 0s, then 1s for every other attempt.
+				b:      backoff.New(1<<30, 0.5, 1*time.Second, 5*time.Second),
+				expect: (1<<30 - 1) * time.Second,
+			},
+			{
+				name:   "Immediate",
+				b:      backoff.New(math.MaxUint64, 2, 0, 0),
+				expect: 0,
+			},
+		} {
+			total, ok := tc.b.EstimateTotal()
+			if !ok {
+				t.Errorf("Test #%d (%s): expected EstimateTotal to return true", i+1, tc.name)
+			}
+			if total != tc.expect {
+				t.Errorf("Test #%d (%s): expected total to be \"%s\", but got \"%s\"", i+1, tc.name, tc.expect, total)
+			}
+		}
+	})
+}
+
+func TestBackoff_NextCause(t *testing.T) {
+	t.Run("Returns the cause when the context is cancelled", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+
+		cause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(cause)
+
+		ok, err := b.NextCause(ctx)
+		if ok {
+			t.Error("expected NextCause to return false when the context is cancelled")
+		}
+		if !errors.Is(err, cause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", cause, err)
+		}
+	})
+
+	t.Run("Returns the deadline error when the context times out", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		if _, err := b.NextCause(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("Returns the cause when a wait reaches the deadline", func(t *testing.T) {
+		cause := errors.New("request budget spent")
+		for i := 0; i < 5; i++ {
+			b := backoff.New(0, 2, time.Second, time.Second)
+			b.NextN(1)
+
+			ctx, cancel := context.WithTimeoutCause(context.Background(), 10*time.Millisecond, cause)
+			ok, err := b.NextCause(ctx)
+			cancel()
+			if ok {
+				t.Fatalf("Test #%d: expected NextCause to return false", i+1)
+			}
+			if err != cause {
+				t.Errorf("Test #%d: expected error to be \"%v\", but got \"%v\"", i+1, cause, err)
+			}
+		}
+	})
+
+	t.Run("Returns nil when MaxAttempts is reached", func(t *testing.T) {
+		b := newBackoffWithMockTimer(1, 0, 0, 0)
+
+		ctx := context.Background()
+		if ok, err := b.NextCause(ctx); !ok || err != nil {
+			t.Fatalf("expected first attempt to return (true, nil), but got (%t, %v)", ok, err)
+		}
+		if ok, err := b.NextCause(ctx); ok || err != nil {
+			t.Errorf("expected second attempt to return (false, nil), but got (%t, %v)", ok, err)
+		}
+	})
+}
+
+func TestBackoff_NextErr(t *testing.T) {
+	t.Run("Returns the cause when the context is cancelled", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+
+		cause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(cause)
+
+		if err := b.NextErr(ctx); !errors.Is(err, cause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", cause, err)
+		}
+	})
+
+	t.Run("Returns ErrMaxAttempts when MaxAttempts is reached", func(t *testing.T) {
+		b := newBackoffWithMockTimer(1, 0, 0, 0)
+
+		ctx := context.Background()
+		if err := b.NextErr(ctx); err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		if err := b.NextErr(ctx); !errors.Is(err, backoff.ErrMaxAttempts) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", backoff.ErrMaxAttempts, err)
+		}
+	})
+
+	t.Run("Returns ErrMaxCappedWaits when MaxCappedWaits is reached", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 2*time.Second)
+		b.MaxCappedWaits = 1
+
+		var err error
+		for i := 0; i < 10 && err == nil; i++ {
+			err = b.NextErr(context.Background())
+		}
+		if !errors.Is(err, backoff.ErrMaxCappedWaits) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", backoff.ErrMaxCappedWaits, err)
+		}
+	})
+}
+
+func TestBackoff_Succeeded(t *testing.T) {
+	t.Run("Resets immediately when ResetAfter is zero", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		b.Succeeded()
+
+		if b.Attempt() != 0 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 0, b.Attempt())
+		}
+	})
+
+	t.Run("Does not reset before ResetAfter has passed", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+		b.ResetAfter = time.Hour
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		b.Succeeded()
+		if b.Attempt() != 2 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+			return
+		}
+
+		// The attempt flapped, so the backoff should keep growing.
+		b.Next(ctx)
+		if b.Attempt() != 3 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 3, b.Attempt())
+		}
+	})
+
+	t.Run("Resets on Next once ResetAfter has passed", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+		b.ResetAfter = 10 * time.Millisecond
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		b.Succeeded()
+		if b.Attempt() != 2 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+			return
+		}
+
+		// Simulate the attempt succeeding for longer than ResetAfter.
+		time.Sleep(20 * time.Millisecond)
+
+		b.Next(ctx)
+		if b.Attempt() != 1 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 1, b.Attempt())
+		}
+	})
+
+	t.Run("Does not count the wait before the attempt", func(t *testing.T) {
+		clock := newMockClock()
+		b := backoff.New(0, 2, 10*time.Second, time.Minute)
+		b.Timer = newClockTimer(clock)
+		b.Clock = clock
+		b.ResetAfter = 5 * time.Second
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		b.Next(ctx)
+		// The last wait was 20s, but the attempt only just succeeded.
+		b.Succeeded()
+		if b.Attempt() != 3 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 3, b.Attempt())
+			return
+		}
+
+		// The attempt failed before ResetAfter passed.
+		clock.Advance(time.Second)
+		b.Next(ctx)
+		if b.Attempt() != 4 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 4, b.Attempt())
+			return
+		}
+
+		b.Succeeded()
+		clock.Advance(b.ResetAfter)
+		b.Next(ctx)
+		if b.Attempt() != 1 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 1, b.Attempt())
+		}
+	})
+
+	t.Run("Measures from the first call to Succeeded", func(t *testing.T) {
+		clock := newMockClock()
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+		b.Clock = clock
+		b.ResetAfter = 10 * time.Second
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		b.Succeeded()
+		clock.Advance(b.ResetAfter)
+		b.Succeeded()
+		if b.Attempt() != 0 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 0, b.Attempt())
+		}
+	})
+
+	t.Run("Does not reset without Succeeded", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		if b.Attempt() != 2 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+		}
+	})
+}
+
+func TestBackoff_InitialDelay(t *testing.T) {
+	timer := &mockTimer{}
+	b := backoff.New(3, 2, 1*time.Second, 5*time.Second)
+	b.Timer = timer
+	b.InitialDelay = 100 * time.Millisecond
+	// Ensure InitialDelay is not affected by Jitter or Min.
+	b.Jitter = backoff.JitterFull
+	b.Rand = fixedRand(0)
+
+	if d := b.Duration(); d != b.InitialDelay {
+		t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.InitialDelay, d)
+		return
+	}
+
+	ctx := context.Background()
+	for b.Next(ctx) {
+	}
+
+	expect := []time.Duration{100 * time.Millisecond, 1 * time.Second, 1 * time.Second}
+	if len(timer.durations) != len(expect) {
+		t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+	}
+	for i, d := range timer.durations {
+		if d != expect[i] {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+		}
+	}
+
+	if total, _ := b.EstimateTotal(); total != 6100*time.Millisecond {
+		t.Errorf("expected total to be \"%s\", but got \"%s\"", 6100*time.Millisecond, total)
+	}
+}
+
+func TestBackoff_Round(t *testing.T) {
+	t.Run("Rounds Duration", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 1.337, 1*time.Second, 5*time.Second)
+		b.Round = 100 * time.Millisecond
+
+		b.Next(context.Background())
+		if d, expect := b.Duration(), 1300*time.Millisecond; d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+	})
+
+	t.Run("Rounds after Jitter", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(3, 2, 1*time.Second, 5*time.Second)
+		b.Timer = timer
+		b.Round = time.Second
+		b.Jitter = backoff.JitterFull
+		b.Rand = fixedRand(0.7)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		// 2s * 0.7 = 1.4s -> 1s, 4s * 0.7 = 2.8s -> 3s
+		expect := []time.Duration{1 * time.Second, 3 * time.Second}
+		for i, d := range timer.durations {
+			if d != expect[i] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+			}
+		}
+	})
+
+	t.Run("Rounds before clamping", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2.2, 1*time.Second, 5*time.Second)
+		b.Round = 5 * time.Second
+
+		b.Next(context.Background())
+		// 2.2s is rounded to 0s, then clamped to Min.
+		if d := b.Duration(); d != b.Min {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Min, d)
+		}
+	})
+}
+
+func TestMustNew(t *testing.T) {
+	t.Run("Returns a valid Backoff", func(t *testing.T) {
+		if b := backoff.MustNew(_maxAttempts, _factor, _min, _max); b == nil {
+			t.Error("expected backoff to not be nil")
+		}
+	})
+
+	t.Run("Panics on an invalid Backoff", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected MustNew to panic")
+			}
+		}()
+		backoff.MustNew(_maxAttempts, _factor, _max, _min)
+	})
+}
+
+func TestNewConstant(t *testing.T) {
+	b := backoff.NewConstant(4, 250*time.Millisecond)
+	timer := &mockTimer{}
+	b.Timer = timer
+	if err := b.Validate(); err != nil {
+		t.Errorf("expected no error, but got \"%v\"", err)
+	}
+
+	for b.Next(context.Background()) {
+	}
+	if len(timer.durations) != 3 {
+		t.Fatalf("expected \"%d\" delays, but got \"%d\"", 3, len(timer.durations))
+	}
+	for i, d := range timer.durations {
+		if d != 250*time.Millisecond {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, 250*time.Millisecond, d)
+		}
+	}
+}
+
+func TestBackoff_ZeroMinMax(t *testing.T) {
+	for i, tc := range []struct {
+		name   string
+		modify func(b *backoff.Backoff)
+	}{
+		{name: "Factor", modify: func(*backoff.Backoff) {}},
+		{name: "Large Factor", modify: func(b *backoff.Backoff) { b.Factor = math.MaxFloat64 }},
+		{name: "Base", modify: func(b *backoff.Backoff) { b.Base = 10 }},
+		{name: "Jitter", modify: func(b *backoff.Backoff) { b.Jitter, b.JitterAbsolute = backoff.JitterEqual, time.Second }},
+	} {
+		timer := &mockTimer{}
+		b := backoff.New(10, 2, 0, 0)
+		b.Timer = timer
+		tc.modify(b)
+
+		var attempts int
+		for b.Next(context.Background()) {
+			attempts++
+		}
+		if attempts != 10 {
+			t.Errorf("Test #%d (%s): expected \"%d\" attempts, but got \"%d\"", i+1, tc.name, 10, attempts)
+		}
+		if len(timer.durations) != 0 {
+			t.Errorf("Test #%d (%s): expected no delays, but got %v", i+1, tc.name, timer.durations)
+		}
+		if d, _ := b.EstimateTotal(); d != 0 {
+			t.Errorf("Test #%d (%s): expected total to be \"%s\", but got \"%s\"", i+1, tc.name, time.Duration(0), d)
+		}
+	}
+
+	// A Strategy is not affected, as it does not use Min or Max.
+	b := backoff.NewTruncatedExponential(time.Second, 0, 0)
+	if d := b.DelayAt(3); d != 8*time.Second {
+		t.Errorf("expected duration to be \"%s\", but got \"%s\"", 8*time.Second, d)
+	}
+}
+
+func TestBackoff_ZeroFactor(t *testing.T) {
+	b := newBackoffWithMockTimer(0, 0, 1*time.Second, 5*time.Second)
+	if err := b.Validate(); err == nil || !strings.Contains(err.Error(), "NewConstant") {
+		t.Errorf("expected an error pointing to NewConstant, but got \"%v\"", err)
+	}
+
+	// A zero Factor is rejected by Validate, but if it is used anyway every
+	// attempt is delayed by Min.
+	for i := uint64(1); i <= 5; i++ {
+		if d := b.DelayAt(i); d != 1*time.Second {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i, 1*time.Second, d)
+		}
+	}
+}
+
+func TestBackoff_Validate(t *testing.T) {
+	for i, tc := range []struct {
+		name   string
+		modify func(b *backoff.Backoff)
+		valid  bool
+	}{
+		{
+			name:   "Valid",
+			modify: func(*backoff.Backoff) {},
+			valid:  true,
+		},
+		{
+			name:   "NaN Factor",
+			modify: func(b *backoff.Backoff) { b.Factor = math.NaN() },
+		},
+		{
+			name:   "Infinite Factor",
+			modify: func(b *backoff.Backoff) { b.Factor = math.Inf(1) },
+		},
+		{
+			name:   "Zero Factor",
+			modify: func(b *backoff.Backoff) { b.Factor = 0 },
+		},
+		{
+			name:   "Negative Factor",
+			modify: func(b *backoff.Backoff) { b.Factor = -2 },
+		},
+		{
+			name:   "Factor below 1",
+			modify: func(b *backoff.Backoff) { b.Factor = 0.5 },
+			valid:  true,
+		},
+		{
+			name:   "Negative FactorJitter",
+			modify: func(b *backoff.Backoff) { b.FactorJitter = -1 },
+		},
+		{
+			name:   "NaN Base",
+			modify: func(b *backoff.Backoff) { b.Base = math.NaN() },
+		},
+		{
+			name:   "Negative Base",
+			modify: func(b *backoff.Backoff) { b.Base = -1 },
+		},
+		{
+			name:   "Negative Min",
+			modify: func(b *backoff.Backoff) { b.Min = -1 },
+		},
+		{
+			name:   "Negative Max",
+			modify: func(b *backoff.Backoff) { b.Max = -1 },
+		},
+		{
+			name:   "InitialDelay with DelayFirst",
+			modify: func(b *backoff.Backoff) { b.InitialDelay, b.DelayFirst = time.Second, true },
+		},
+		{
+			name:   "Negative HardMax",
+			modify: func(b *backoff.Backoff) { b.HardMax = -1 },
+		},
+		{
+			name:   "Min greater than Max",
+			modify: func(b *backoff.Backoff) { b.Min, b.Max = b.Max, b.Min },
+		},
+		{
+			name:   "Zero Max",
+			modify: func(b *backoff.Backoff) { b.Max = 0 },
+			valid:  true,
+		},
+		{
+			name:   "Negative InitialDelay",
+			modify: func(b *backoff.Backoff) { b.InitialDelay = -1 },
+		},
+		{
+			name:   "Negative MaxElapsed",
+			modify: func(b *backoff.Backoff) { b.MaxElapsed = -1 },
+		},
+		{
+			name:   "Negative Round",
+			modify: func(b *backoff.Backoff) { b.Round = -1 },
+		},
+		{
+			name:   "Negative JitterAbsolute",
+			modify: func(b *backoff.Backoff) { b.JitterAbsolute = -1 },
+		},
+		{
+			name:   "JitterFloor above 1",
+			modify: func(b *backoff.Backoff) { b.JitterFloor = 1.5 },
+		},
+		{
+			name:   "Unknown Jitter",
+			modify: func(b *backoff.Backoff) { b.Jitter = 255 },
+		},
+	} {
+		b := newBackoffWithMockTimer(_maxAttempts, _factor, _min, _max)
+		tc.modify(b)
+
+		err := b.Validate()
+		if tc.valid && err != nil {
+			t.Errorf("Test #%d (%s): expected no error, but got \"%v\"", i+1, tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Test #%d (%s): expected an error", i+1, tc.name)
+		}
+	}
+}
+
+func TestBackoff_WithMaxAttempts(t *testing.T) {
+	b := newBackoffWithMockTimer(10, 2, time.Second, time.Minute)
+	b.NextN(2)
+
+	c := b.WithMaxAttempts(3).WithFactor(3).WithMin(2 * time.Second).WithMax(time.Hour)
+	if c == b {
+		t.Fatal("expected a clone to be returned")
+	}
+	if c.MaxAttempts != 3 || c.Factor != 3 || c.Min != 2*time.Second || c.Max != time.Hour {
+		t.Errorf("expected the clone to be changed, but got MaxAttempts \"%d\", Factor \"%v\", Min \"%s\" and Max \"%s\"", c.MaxAttempts, c.Factor, c.Min, c.Max)
+	}
+	if c.Attempt() != 0 {
+		t.Errorf("expected the clone to be reset, but its attempt is \"%d\"", c.Attempt())
+	}
+	if b.MaxAttempts != 10 || b.Factor != 2 || b.Min != time.Second || b.Max != time.Minute {
+		t.Error("expected the original Backoff to not be changed")
+	}
+	if b.Attempt() != 2 {
+		t.Errorf("expected the original attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+	}
+}
+
+func TestBackoff_Clone(t *testing.T) {
+	b := backoff.New(_maxAttempts, _factor, _min, _max)
+	b.Timer = newMockTimer()
+	b.Jitter = backoff.JitterEqual
+
+	ctx := context.Background()
+	b.Next(ctx)
+	b.Next(ctx)
+
+	c := b.Clone()
+	if c == b {
+		t.Fatal("expected clone to be a different instance")
+	}
+	if c.Attempt() != 0 {
+		t.Errorf("expected clone's attempt to be \"%d\", but got \"%d\"", 0, c.Attempt())
+	}
+	if c.MaxAttempts != b.MaxAttempts || c.Factor != b.Factor || c.Min != b.Min || c.Max != b.Max || c.Jitter != b.Jitter {
+		t.Error("expected clone to have the same configuration")
+	}
+
+	// Ensure the clone does not modify the original.
+	c.Next(ctx)
+	if b.Attempt() != 2 {
+		t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+	}
+
+	t.Run("Gets a new real timer", func(t *testing.T) {
+		b := backoff.New(_maxAttempts, _factor, _min, _max)
+		if c := b.Clone(); c.Timer == b.Timer {
+			t.Error("expected clone to not share the real timer")
+		}
+	})
+
+	t.Run("Uses NewTimer", func(t *testing.T) {
+		b := backoff.New(4, 2, 1*time.Second, time.Minute)
+		b.NewTimer = func() backoff.Timer { return &mockTimer{} }
+		b.Timer = b.NewTimer()
+
+		clones := make([]*backoff.Backoff, 8)
+		for i := range clones {
+			clones[i] = b.Clone()
+			if clones[i].Timer == b.Timer {
+				t.Fatal("expected clone to not share the timer")
+			}
+		}
+
+		var wg sync.WaitGroup
+		for _, c := range clones {
+			wg.Add(1)
+			go func(c *backoff.Backoff) {
+				defer wg.Done()
+				for c.Next(ctx) {
+				}
+			}(c)
+		}
+		wg.Wait()
+
+		expect := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+		for i, c := range clones {
+			durations := c.Timer.(*mockTimer).durations
+			if len(durations) != len(expect) {
+				t.Errorf("Test #%d: expected timer to be started \"%d\" times, but got \"%d\"", i+1, len(expect), len(durations))
+				continue
+			}
+			for j, d := range durations {
+				if d != expect[j] {
+					t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[j], d)
+				}
+			}
+		}
+	})
+}
+
+func TestBackoff_Reconfigure(t *testing.T) {
+	t.Run("Updates and resets", func(t *testing.T) {
+		b := newBackoffWithMockTimer(_maxAttempts, _factor, _min, _max)
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+
+		if err := b.Reconfigure(3, 2*time.Second, 10*time.Second); err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		if b.Factor != 3 || b.Min != 2*time.Second || b.Max != 10*time.Second {
+			t.Error("expected Reconfigure to update Factor, Min and Max")
+		}
+		if b.Attempt() != 0 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 0, b.Attempt())
+		}
+
+		b.Next(ctx)
+		if d, expect := b.Duration(), 6*time.Second; d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+	})
+
+	t.Run("Leaves the Backoff unchanged if invalid", func(t *testing.T) {
+		b := newBackoffWithMockTimer(_maxAttempts, _factor, _min, _max)
+		b.Next(context.Background())
+
+		if err := b.Reconfigure(3, 10*time.Second, 2*time.Second); err == nil {
+			t.Fatal("expected an error")
+		}
+		if b.Factor != _factor || b.Min != _min || b.Max != _max {
+			t.Error("expected Reconfigure to not update an invalid configuration")
+		}
+		if b.Attempt() != 1 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 1, b.Attempt())
+		}
+	})
+}
+
+func TestBackoff_Snapshot(t *testing.T) {
+	clock := newMockClock()
+	b := newBackoffWithMockTimer(5, 2, 1*time.Second, 4*time.Second)
+	b.Clock = clock
+
+	if s := b.Snapshot(); s != (backoff.Snapshot{RemainingAttempts: 5}) {
+		t.Errorf("expected an empty snapshot, but got %+v", s)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		b.Next(ctx)
+		_, _, d := b.Status()
+		clock.Advance(d)
+	}
+
+	// 0s, 2s, 4s, 4s
+	expect := backoff.Snapshot{
+		Attempt:           4,
+		NextDelay:         4 * time.Second,
+		Elapsed:           10 * time.Second,
+		Saturated:         true,
+		RemainingAttempts: 1,
+	}
+	if s := b.Snapshot(); s != expect {
+		t.Errorf("expected snapshot to be %+v, but got %+v", expect, s)
+	}
+}
+
+func TestBackoff_Status(t *testing.T) {
+	t.Run("Interrupted while waiting", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Hour, time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		b.Next(ctx)
+		if attempt, waiting, lastDelay := b.Status(); attempt != 1 || waiting || lastDelay != 0 {
+			t.Errorf("expected status to be (1, false, 0s), but got (%d, %t, %s)", attempt, waiting, lastDelay)
+			return
+		}
+
+		done := make(chan bool)
+		go func() {
+			done <- b.Next(ctx)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		<-done
+
+		if attempt, waiting, lastDelay := b.Status(); attempt != 2 || !waiting || lastDelay != time.Hour {
+			t.Errorf("expected status to be (2, true, 1h0m0s), but got (%d, %t, %s)", attempt, waiting, lastDelay)
+		}
+	})
+
+	t.Run("Stopped at the limit", func(t *testing.T) {
+		b := newBackoffWithMockTimer(2, 2, 1*time.Second, 5*time.Second)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+			if _, _, lastDelay := b.Status(); b.Attempt() == 2 && lastDelay != 2*time.Second {
+				t.Errorf("expected last delay to be \"%s\", but got \"%s\"", 2*time.Second, lastDelay)
+			}
+		}
+
+		if attempt, waiting, lastDelay := b.Status(); attempt != 2 || waiting || lastDelay != 0 {
+			t.Errorf("expected status to be (2, false, 0s), but got (%d, %t, %s)", attempt, waiting, lastDelay)
+		}
+	})
+}
+
+func TestBackoff_TotalAttempts(t *testing.T) {
+	b := newBackoffWithMockTimer(2, 0, 0, 0)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		for b.Next(ctx) {
+		}
+		b.Reset()
+	}
+
+	if b.Attempt() != 0 {
+		t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 0, b.Attempt())
+	}
+	if b.TotalAttempts() != 6 {
+		t.Errorf("expected total attempts to be \"%d\", but got \"%d\"", 6, b.TotalAttempts())
+		return
+	}
+	if c := b.Clone(); c.TotalAttempts() != 0 {
+		t.Errorf("expected clone's total attempts to be \"%d\", but got \"%d\"", 0, c.TotalAttempts())
+	}
+
+	b.Next(ctx)
+	b.ResetAll()
+	if b.Attempt() != 0 || b.TotalAttempts() != 0 {
+		t.Errorf("expected ResetAll to reset attempts, but got (%d, %d)", b.Attempt(), b.TotalAttempts())
+	}
+}
+
+func TestBackoff_DelayFirst(t *testing.T) {
+	timer := &mockTimer{}
+	b := backoff.New(4, 2, 1*time.Second, 5*time.Second)
+	b.Timer = timer
+	b.DelayFirst = true
+	b.MaxCappedWaits = 1
+
+	if d := b.Duration(); d != 2*time.Second {
+		t.Errorf("expected the first duration to be \"%s\", but got \"%s\"", 2*time.Second, d)
+	}
+
+	var attempts int
+	for b.Next(context.Background()) {
+		attempts++
+	}
+
+	// The delays of attempts 2, 3 and 4 without DelayFirst, then the second
+	// wait at Max exceeds MaxCappedWaits.
+	expect := []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second}
+	if len(timer.durations) != len(expect) {
+		t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+	}
+	for i, d := range timer.durations {
+		if d != expect[i] {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+		}
+	}
+	if attempts != 3 {
+		t.Errorf("expected \"%d\" attempts, but got \"%d\"", 3, attempts)
+	}
+	if d := b.DelayAt(0); d != 2*time.Second {
+		t.Errorf("expected DelayAt to return \"%s\", but got \"%s\"", 2*time.Second, d)
+	}
+}
+
+func TestBackoff_HardMax(t *testing.T) {
+	timer := &mockTimer{}
+	b := backoff.New(0, 2, 1*time.Second, 8*time.Second)
+	b.Timer = timer
+	b.InitialDelay = 5 * time.Second
+	b.HardMax = 3 * time.Second
+	b.Jitter = backoff.JitterEqual
+	b.Rand = fixedRand(1)
+	b.MaxCappedWaits = 2
+
+	var attempts int
+	for b.Next(context.Background()) {
+		attempts++
+	}
+
+	// The schedule is unchanged, so MaxCappedWaits still counts the waits
+	// where the delay was Max.
+	expect := []time.Duration{3 * time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second, 3 * time.Second}
+	if len(timer.durations) != len(expect) {
+		t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+	}
+	for i, d := range timer.durations {
+		if d != expect[i] {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+		}
+	}
+	if attempts != 5 {
+		t.Errorf("expected \"%d\" attempts, but got \"%d\"", 5, attempts)
+	}
+	if d := b.DelayAt(10); d != 3*time.Second {
+		t.Errorf("expected DelayAt to return \"%s\", but got \"%s\"", 3*time.Second, d)
+	}
+}
+
+func TestBackoff_MaxCappedWaits(t *testing.T) {
+	t.Run("Aborts after consecutive waits at Max", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 4*time.Second)
+		b.MaxCappedWaits = 2
+
+		var i uint64
+		ctx := context.Background()
+		for b.Next(ctx) {
+			i++
+			if i > 10 {
+				t.Fatal("expected Next to abort after \"2\" waits at Max")
+			}
+		}
+
+		// 0s, 2s, 4s, 4s
+		if i != 4 {
+			t.Errorf("expected number of attempts to be \"%d\", but got \"%d\"", 4, i)
+		}
+	})
+
+	t.Run("Resets when the delay drops below Max", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 4*time.Second)
+		b.MaxCappedWaits = 1
+
+		ctx := context.Background()
+		for i := 0; i < 3; i++ {
+			if !b.Next(ctx) {
+				t.Fatalf("Test #%d: expected Next to return true", i+1)
+			}
+		}
+		// Raise Max so the next delay is no longer capped.
+		b.Max = time.Minute
+		for i := 0; i < 3; i++ {
+			if !b.Next(ctx) {
+				t.Fatalf("Test #%d: expected Next to return true", i+4)
+			}
+		}
+	})
+}
+
+func TestBackoff_MinRemainingForRetry(t *testing.T) {
+	t.Run("Gives up before a wait that leaves too little time", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(0, 2, 20*time.Millisecond, time.Second)
+		b.Timer = timer
+		b.MinRemainingForRetry = 50 * time.Millisecond
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		if err := b.NextErr(ctx); err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), 60*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if err := b.NextErr(ctx); err != backoff.ErrDeadline {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", backoff.ErrDeadline, err)
+		}
+		if d := time.Since(start); d > 10*time.Millisecond {
+			t.Errorf("expected Next to give up immediately, but it took \"%s\"", d)
+		}
+		if len(timer.durations) != 0 {
+			t.Errorf("expected timer to not be started, but it was started \"%d\" times", len(timer.durations))
+		}
+		if b.Attempt() != 1 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 1, b.Attempt())
+		}
+	})
+
+	t.Run("Waits when enough time is left", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 20*time.Millisecond, time.Second)
+		b.MinRemainingForRetry = 50 * time.Millisecond
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		for i := 0; i < 3; i++ {
+			if err := b.NextErr(ctx); err != nil {
+				t.Fatalf("Test #%d: expected no error, but got \"%v\"", i+1, err)
+			}
+		}
+	})
+
+	t.Run("Ignores contexts without a deadline", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 20*time.Millisecond, time.Second)
+		b.MinRemainingForRetry = time.Hour
+
+		var attempts int
+		for b.Next(context.Background()) {
+			attempts++
+		}
+		if attempts != 3 {
+			t.Errorf("expected \"%d\" attempts, but got \"%d\"", 3, attempts)
+		}
+	})
+
+	t.Run("Retry wraps the last error", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 20*time.Millisecond, time.Second)
+		b.MinRemainingForRetry = time.Hour
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		fn, calls := failN(10)
+		err := b.Retry(ctx, fn)
+		if !errors.Is(err, backoff.ErrDeadline) || !errors.Is(err, errRetry) {
+			t.Errorf("expected error to wrap \"%v\" and \"%v\", but got \"%v\"", backoff.ErrDeadline, errRetry, err)
+		}
+		if *calls != 1 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 1, *calls)
+		}
+	})
+}
+
+func TestBackoff_OnSaturate(t *testing.T) {
+	for i, tc := range []struct {
+		name   string
+		factor float64
+		max    time.Duration
+	}{
+		{name: "Max", factor: 2, max: 4 * time.Second},
+		{name: "Overflow", factor: math.MaxFloat64, max: 0},
+	} {
+		b := newBackoffWithMockTimer(0, tc.factor, 1*time.Second, tc.max)
+		var saturated []uint64
+		b.OnSaturate = func(attempt uint64) {
+			saturated = append(saturated, attempt)
+		}
+
+		ctx := context.Background()
+		for j := 0; j < 6; j++ {
+			b.Next(ctx)
+		}
+		b.Reset()
+		for j := 0; j < 6; j++ {
+			b.Next(ctx)
+		}
+
+		// 0s, 2s, 4s, 4s, ... for Max, or 0s, Max, ... when overflowing.
+		expect := uint64(3)
+		if tc.max == 0 {
+			expect = 2
+		}
+		if len(saturated) != 2 || saturated[0] != expect || saturated[1] != expect {
+			t.Errorf("Test #%d (%s): expected OnSaturate to be called with [%d %d], but got %v", i+1, tc.name, expect, expect, saturated)
+		}
+	}
+}
+
+func TestBackoff_NextCapped(t *testing.T) {
+	timer := &mockTimer{}
+	b := backoff.New(0, 2, 1*time.Second, time.Minute)
+	b.Timer = timer
+
+	ctx := context.Background()
+	b.Next(ctx)
+	b.Next(ctx)
+	b.NextCapped(ctx, 3*time.Second)
+	b.NextCapped(ctx, time.Minute)
+	b.Next(ctx)
+
+	expect := []time.Duration{2 * time.Second, 3 * time.Second, 8 * time.Second, 16 * time.Second}
+	if len(timer.durations) != len(expect) {
+		t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+	}
+	for i, d := range timer.durations {
+		if d != expect[i] {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+		}
+	}
+	if b.Attempt() != 5 {
+		t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 5, b.Attempt())
+	}
+}
+
+func TestBackoff_NextUntil(t *testing.T) {
+	clock := newMockClock()
+	timer := &mockTimer{}
+	b := backoff.New(0, 2, 1*time.Second, time.Minute)
+	b.Timer = timer
+	b.Clock = clock
+	deadline := clock.Now().Add(5 * time.Second)
+
+	var attempts int
+	for b.NextUntil(context.Background(), deadline) {
+		attempts++
+		_, _, d := b.Status()
+		clock.Advance(d)
+	}
+
+	// The delay of 4s before the third attempt would end 1s after the
+	// deadline.
+	expect := []time.Duration{2 * time.Second, 3 * time.Second}
+	if len(timer.durations) != len(expect) {
+		t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+	}
+	for i, d := range timer.durations {
+		if d != expect[i] {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+		}
+	}
+	if attempts != 3 {
+		t.Errorf("expected \"%d\" attempts, but got \"%d\"", 3, attempts)
+	}
+	if !clock.Now().Equal(deadline) {
+		t.Errorf("expected the last attempt to start at the deadline, but it started %s after", clock.Now().Sub(deadline))
+	}
+}
+
+func TestBackoff_MaxElapsed(t *testing.T) {
+	// next calls NextErr and advances the clock by the delay that was waited
+	// for, like a real timer would.
+	next := func(b *backoff.Backoff, clock *mockClock) error {
+		err := b.NextErr(context.Background())
+		_, _, d := b.Status()
+		clock.Advance(d)
+		return err
+	}
+
+	for i, tc := range []struct {
+		name        string
+		maxAttempts uint64
+		maxElapsed  time.Duration
+		attempts    uint64
+		expect      error
+	}{
+		{
+			name:        "MaxAttempts is reached first",
+			maxAttempts: 3,
+			maxElapsed:  time.Hour,
+			attempts:    3,
+			expect:      backoff.ErrMaxAttempts,
+		},
+		{
+			name:        "MaxElapsed is reached first",
+			maxAttempts: 10,
+			maxElapsed:  10 * time.Second,
+			attempts:    3,
+			expect:      backoff.ErrMaxElapsed,
+		},
+		{
+			name:        "MaxAttempts takes precedence",
+			maxAttempts: 3,
+			maxElapsed:  6 * time.Second,
+			attempts:    3,
+			expect:      backoff.ErrMaxAttempts,
+		},
+		{
+			name:       "MaxElapsed without MaxAttempts",
+			maxElapsed: time.Minute,
+			attempts:   5,
+			expect:     backoff.ErrMaxElapsed,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newMockClock()
+			timer := &mockTimer{}
+			b := backoff.New(tc.maxAttempts, 2, 1*time.Second, time.Hour)
+			b.Timer = timer
+			b.Clock = clock
+			b.MaxElapsed = tc.maxElapsed
+
+			var attempts uint64
+			var err error
+			for {
+				if err = next(b, clock); err != nil {
+					break
+				}
+				attempts++
+			}
+
+			if attempts != tc.attempts {
+				t.Errorf("Test #%d: expected number of attempts to be \"%d\", but got \"%d\"", i+1, tc.attempts, attempts)
+			}
+			if !errors.Is(err, tc.expect) {
+				t.Errorf("Test #%d: expected error to be \"%v\", but got \"%v\"", i+1, tc.expect, err)
+			}
+			if len(timer.durations) != int(tc.attempts-1) {
+				t.Errorf("Test #%d: expected timer to be started \"%d\" times, but got \"%d\"", i+1, tc.attempts-1, len(timer.durations))
+			}
+		})
+	}
+
+	t.Run("Reset restarts the elapsed time", func(t *testing.T) {
+		clock := newMockClock()
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, time.Hour)
+		b.Clock = clock
+		b.MaxElapsed = 10 * time.Second
+
+		for next(b, clock) == nil {
+		}
+		b.Reset()
+		if err := next(b, clock); err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+	})
+}
+
+func TestBackoff_DelayAt(t *testing.T) {
+	b := newBackoffWithMockTimer(0, 2, 1*time.Second, 10*time.Second)
+	b.InitialDelay = 500 * time.Millisecond
+	b.Jitter = backoff.JitterFull
+	b.FactorJitter = 0.5
+	b.Rand = fixedRand(0)
+
+	ctx := context.Background()
+	b.Next(ctx)
+	b.Next(ctx)
+
+	for _, tc := range []struct {
+		attempt uint64
+		expect  time.Duration
+	}{
+		{attempt: 5, expect: 10 * time.Second},
+		{attempt: 0, expect: 500 * time.Millisecond},
+		{attempt: 2, expect: 4 * time.Second},
+		{attempt: 1, expect: 2 * time.Second},
+		{attempt: 3, expect: 8 * time.Second},
+	} {
+		if d := b.DelayAt(tc.attempt); d != tc.expect {
+			t.Errorf("attempt %d: expected delay to be \"%s\", but got \"%s\"", tc.attempt, tc.expect, d)
+		}
+	}
+	if b.Attempt() != 2 {
+		t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+	}
+}
+
+func TestBackoff_NilTimer(t *testing.T) {
+	b := &backoff.Backoff{Min: time.Millisecond, Max: 2 * time.Millisecond, Factor: 2}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if !b.Next(ctx) {
+			t.Fatalf("Test #%d: expected Next to return true", i+1)
+		}
+	}
+	if b.Timer == nil {
+		t.Error("expected Timer to be initialized")
+	}
+
+	t.Run("Uses NewTimer", func(t *testing.T) {
+		b := &backoff.Backoff{Min: time.Second, Max: time.Minute, Factor: 2}
+		b.NewTimer = func() backoff.Timer { return &mockTimer{} }
+
+		b.Sleep()
+		b.Sleep()
+		if timer, ok := b.Timer.(*mockTimer); !ok || len(timer.durations) != 1 {
+			t.Error("expected Timer to be created using NewTimer")
+		}
+	})
+}
+
+func TestBackoff_Base(t *testing.T) {
+	for i, tc := range []struct {
+		base   float64
+		expect []time.Duration
+	}{
+		{
+			base:   0,
+			expect: []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second},
+		},
+		{
+			base:   1,
+			expect: []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second},
+		},
+		{
+			base:   1.5,
+			expect: []time.Duration{3 * time.Second, 6 * time.Second, 12 * time.Second, 20 * time.Second},
+		},
+		{
+			// Clamped to Min.
+			base:   0.25,
+			expect: []time.Duration{1 * time.Second, 1 * time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			// Overflows are clamped to Max.
+			base:   math.MaxFloat64,
+			expect: []time.Duration{20 * time.Second, 20 * time.Second, 20 * time.Second, 20 * time.Second},
+		},
+	} {
+		timer := &mockTimer{}
+		b := backoff.New(5, 2, 1*time.Second, 20*time.Second)
+		b.Timer = timer
+		b.Base = tc.base
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		if len(timer.durations) != len(tc.expect) {
+			t.Errorf("Test #%d: expected timer to be started \"%d\" times, but got \"%d\"", i+1, len(tc.expect), len(timer.durations))
+			continue
+		}
+		for j, d := range timer.durations {
+			if d != tc.expect[j] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, tc.expect[j], d)
+			}
+		}
+		if d := b.DelayAt(1); d != tc.expect[0] {
+			t.Errorf("Test #%d: expected delay to be \"%s\", but got \"%s\"", i+1, tc.expect[0], d)
+		}
+	}
+}
+
+func TestBackoff_NoMinClamp(t *testing.T) {
+	t.Run("Delays go below Min", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(4, 0.5, 1*time.Second, 5*time.Second)
+		b.Timer = timer
+		b.NoMinClamp = true
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		expect := []time.Duration{500 * time.Millisecond, 250 * time.Millisecond, 125 * time.Millisecond}
+		if len(timer.durations) != len(expect) {
+			t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+		}
+		for i, d := range timer.durations {
+			if d != expect[i] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+			}
+		}
+	})
+
+	t.Run("Jitter goes below Min", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(2, 2, 1*time.Second, 5*time.Second)
+		b.Timer = timer
+		b.NoMinClamp = true
+		b.Jitter = backoff.JitterFull
+		b.Rand = fixedRand(0.1)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		if d, expect := timer.durations[0], 200*time.Millisecond; d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+	})
+
+	t.Run("Still clamps to Max", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 10, 1*time.Second, 5*time.Second)
+		b.NoMinClamp = true
+		b.NextN(3)
+
+		if d := b.Duration(); d != b.Max {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Max, d)
+		}
+	})
+}
+
+func TestBackoff_NextWithProgress(t *testing.T) {
+	t.Run("Reports the remaining time", func(t *testing.T) {
+		b := backoff.New(2, 2, 50*time.Millisecond, time.Second)
+
+		var remaining []time.Duration
+		progress := func(d time.Duration) {
+			remaining = append(remaining, d)
+		}
+
+		ctx := context.Background()
+		for b.NextWithProgress(ctx, 10*time.Millisecond, progress) {
+		}
+
+		if len(remaining) < 2 {
+			t.Fatalf("expected progress to be called at least twice, but got \"%d\"", len(remaining))
+		}
+		if remaining[0] != 100*time.Millisecond {
+			t.Errorf("expected first remaining time to be \"%s\", but got \"%s\"", 100*time.Millisecond, remaining[0])
+		}
+		for i := 1; i < len(remaining); i++ {
+			if remaining[i] >= remaining[i-1] {
+				t.Errorf("Test #%d: expected remaining time to decrease, but got \"%s\" after \"%s\"", i+1, remaining[i], remaining[i-1])
+			}
+		}
+	})
+
+	t.Run("Stops on cancellation", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Minute, time.Hour)
+		b.NextN(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		var calls int
+		start := time.Now()
+		if b.NextWithProgress(ctx, 5*time.Millisecond, func(time.Duration) { calls++ }) {
+			t.Fatal("expected NextWithProgress to return false when the context is cancelled")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected NextWithProgress to return promptly, but it took \"%s\"", elapsed)
+		}
+
+		after := calls
+		time.Sleep(20 * time.Millisecond)
+		if calls != after {
+			t.Error("expected progress to not be called after NextWithProgress returned")
+		}
+		if _, waiting, _ := b.Status(); !waiting {
+			t.Error("expected status to report the wait was interrupted")
+		}
+	})
+
+	t.Run("Does not wait past the deadline", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Minute, time.Hour)
+		b.NextN(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		var remaining []time.Duration
+		err := make(chan error, 1)
+		go func() {
+			if b.NextWithProgress(ctx, time.Hour, func(d time.Duration) { remaining = append(remaining, d) }) {
+				err <- errors.New("expected NextWithProgress to return false when the deadline is reached")
+				return
+			}
+			err <- nil
+		}()
+
+		select {
+		case err := <-err:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected NextWithProgress to return once the deadline is reached")
+		}
+		if len(remaining) != 1 || remaining[0] > 30*time.Millisecond {
+			t.Errorf("expected progress to be called once with at most \"%s\", but got %v", 30*time.Millisecond, remaining)
+		}
+	})
+
+	t.Run("Can be nudged", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Minute, time.Hour)
+		b.NextN(1)
+
+		done := make(chan bool, 1)
+		started := make(chan struct{})
+		go func() {
+			done <- b.NextWithProgress(context.Background(), time.Hour, func(time.Duration) { close(started) })
+		}()
+
+		// The wait can be nudged once progress has been called.
+		<-started
+		b.Nudge()
+		select {
+		case ok := <-done:
+			if !ok {
+				t.Error("expected NextWithProgress to return true when nudged")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected NextWithProgress to return once nudged")
+		}
+	})
+}
+
+func TestBackoff_History(t *testing.T) {
+	t.Run("Records every delay", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(5, 2, 1*time.Second, time.Minute)
+		b.Timer = timer
+		b.RecordHistory = true
+		b.Jitter = backoff.JitterFull
+		b.Rand = rand.New(rand.NewSource(1))
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		b.NextCapped(ctx, 500*time.Millisecond)
+		for b.Next(ctx) {
+		}
+
+		history := b.History()
+		if len(history) != 5 {
+			t.Fatalf("expected history to have \"%d\" delays, but got \"%d\"", 5, len(history))
+		}
+		if history[0] != 0 {
+			t.Errorf("expected first delay to be \"%s\", but got \"%s\"", time.Duration(0), history[0])
+		}
+		for i, d := range timer.durations {
+			if history[i+1] != d {
+				t.Errorf("Test #%d: expected delay to be \"%s\", but got \"%s\"", i+1, d, history[i+1])
+			}
+		}
+		if history[2] != 500*time.Millisecond {
+			t.Errorf("expected capped delay to be \"%s\", but got \"%s\"", 500*time.Millisecond, history[2])
+		}
+
+		b.Reset()
+		if h := b.History(); h != nil {
+			t.Errorf("expected history to be cleared by Reset, but got %v", h)
+		}
+	})
+
+	t.Run("Is disabled by default", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, time.Minute)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+		if h := b.History(); h != nil {
+			t.Errorf("expected no history, but got %v", h)
+		}
+	})
+}
+
+func TestBackoff_Sleep(t *testing.T) {
+	timer := &mockTimer{}
+	b := backoff.New(3, 2, 1*time.Second, 5*time.Second)
+	b.Timer = timer
+
+	var i uint64
+	for b.Sleep() {
+		i++
+	}
+
+	if i != b.MaxAttempts {
+		t.Errorf("expected number of attempts to be \"%d\", but got \"%d\"", b.MaxAttempts, i)
+	}
+	if len(timer.durations) != 2 || timer.durations[0] != 2*time.Second || timer.durations[1] != 4*time.Second {
+		t.Errorf("expected timer to be started with [2s 4s], but got %v", timer.durations)
+	}
+}
+
+func TestBackoff_SetAttempt(t *testing.T) {
+	t.Run("Resumes from the attempt", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.NewWithOptions(5, 2, 1*time.Second, time.Minute, backoff.WithStartAttempt(3))
+		b.Timer = timer
+
+		var attempts []uint64
+		for b.Next(context.Background()) {
+			attempts = append(attempts, b.Attempt())
+		}
+
+		if len(attempts) != 2 || attempts[0] != 4 || attempts[1] != 5 {
+			t.Errorf("expected attempts to be [4 5], but got %v", attempts)
+		}
+		if d := timer.durations; len(d) != 2 || d[0] != 8*time.Second || d[1] != 16*time.Second {
+			t.Errorf("expected the timer to be started with [8s 16s], but got %v", d)
+		}
+		if b.TotalAttempts() != 2 {
+			t.Errorf("expected total attempts to be \"%d\", but got \"%d\"", 2, b.TotalAttempts())
+		}
+	})
+
+	t.Run("Resumes the schedule with FactorJitter", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, time.Hour)
+		b.FactorJitter = 0.5
+		b.SetAttempt(3)
+
+		if d, expect := b.Duration(), b.DelayAt(3); d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+		ctx := context.Background()
+		for attempt := uint64(4); attempt <= 6; attempt++ {
+			b.Next(ctx)
+			// Every factor picked after SetAttempt is between 1.5 and 2.5,
+			// so each one is within 0.75 and 1.25 times Factor.
+			expect := b.DelayAt(attempt)
+			lo, hi := time.Duration(float64(expect)*math.Pow(0.75, float64(attempt-3))), time.Duration(float64(expect)*math.Pow(1.25, float64(attempt-3)))
+			if d := b.Duration(); d < lo || d > hi {
+				t.Errorf("Test #%d: expected duration to be between \"%s\" and \"%s\", but got \"%s\"", attempt, lo, hi, d)
+			}
+		}
+	})
+
+	t.Run("Does not exceed MaxAttempts", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, time.Minute)
+		b.SetAttempt(10)
+		if b.Attempt() != 3 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 3, b.Attempt())
+		}
+		if b.Next(context.Background()) {
+			t.Error("expected Next to return false")
+		}
+	})
+}
+
+func TestBackoff_SetFirstDelay(t *testing.T) {
+	timer := &mockTimer{}
+	b := backoff.NewConstant(3, time.Second)
+	b.Timer = timer
+	b.DelayFirst = true
+	b.SetFirstDelay(500 * time.Millisecond)
+
+	if err := b.Validate(); err != nil {
+		t.Fatalf("expected no error, but got \"%v\"", err)
+	}
+	if d := b.Duration(); d != 500*time.Millisecond {
+		t.Errorf("expected duration to be \"%s\", but got \"%s\"", 500*time.Millisecond, d)
+	}
+	for b.Next(context.Background()) {
+	}
+	if d := timer.durations; len(d) != 3 || d[0] != 500*time.Millisecond || d[1] != time.Second || d[2] != time.Second {
+		t.Errorf("expected the timer to be started with [500ms 1s 1s], but got %v", d)
+	}
+
+	b.SetFirstDelay(-time.Second)
+	b.Reset()
+	if d := b.Duration(); d != 0 {
+		t.Errorf("expected duration to be \"%s\", but got \"%s\"", time.Duration(0), d)
+	}
+}
+
+func TestBackoff_Cap(t *testing.T) {
+	for i, tc := range []struct {
+		configure func(b *backoff.Backoff)
+		expect    time.Duration
+		bounded   bool
+	}{
+		{configure: func(*backoff.Backoff) {}, expect: time.Minute, bounded: true},
+		{configure: func(b *backoff.Backoff) { b.HardMax = 10 * time.Second }, expect: 10 * time.Second, bounded: true},
+		{configure: func(b *backoff.Backoff) { b.HardMax = time.Hour }, expect: time.Minute, bounded: true},
+		{configure: func(b *backoff.Backoff) { b.InitialDelay = time.Hour }, expect: time.Hour, bounded: true},
+		{configure: func(b *backoff.Backoff) { b.Max = 0 }, expect: 0, bounded: false},
+		{configure: func(b *backoff.Backoff) { b.Max, b.HardMax = 0, time.Second }, expect: time.Second, bounded: true},
+		{configure: func(b *backoff.Backoff) { b.Min, b.Max = 0, 0 }, expect: 0, bounded: true},
+		{configure: func(b *backoff.Backoff) { b.Min, b.Max, b.InitialDelay = 0, 0, time.Second }, expect: time.Second, bounded: true},
+	} {
+		b := newBackoffWithMockTimer(5, 2, time.Second, time.Minute)
+		tc.configure(b)
+		d, ok := b.Cap()
+		if d != tc.expect || ok != tc.bounded {
+			t.Errorf("Test #%d: expected cap to be \"%s\" (%t), but got \"%s\" (%t)", i+1, tc.expect, tc.bounded, d, ok)
+		}
+	}
+}
+
+func TestBackoff_NextN(t *testing.T) {
+	t.Run("Matches calling Next", func(t *testing.T) {
+		ctx := context.Background()
+		expect := newBackoffWithMockTimer(10, 2, 1*time.Second, time.Minute)
+		for i := 0; i < 4; i++ {
+			expect.Next(ctx)
+		}
+
+		timer := &mockTimer{}
+		b := backoff.New(10, 2, 1*time.Second, time.Minute)
+		b.Timer = timer
+		b.NextN(4)
+
+		if len(timer.durations) != 0 {
+			t.Errorf("expected timer to not be started, but it was started \"%d\" times", len(timer.durations))
+		}
+		if b.Attempt() != expect.Attempt() {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", expect.Attempt(), b.Attempt())
+		}
+		if b.Duration() != expect.Duration() {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect.Duration(), b.Duration())
+		}
+	})
+
+	t.Run("Saturates at MaxAttempts", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, time.Minute)
+		b.NextN(10)
+
+		if b.Attempt() != b.MaxAttempts {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", b.MaxAttempts, b.Attempt())
+		}
+		if b.TotalAttempts() != b.MaxAttempts {
+			t.Errorf("expected total attempts to be \"%d\", but got \"%d\"", b.MaxAttempts, b.TotalAttempts())
+		}
+		if b.Next(context.Background()) {
+			t.Error("expected Next to return false after skipping past MaxAttempts")
+		}
+	})
+
+	t.Run("Skips ahead in constant time", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, time.Minute)
+		b.NextN(math.MaxUint64 - 1)
+		b.NextN(10)
+
+		if b.Attempt() != math.MaxUint64 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", uint64(math.MaxUint64), b.Attempt())
+		}
+		if b.Duration() != time.Minute {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", time.Minute, b.Duration())
+		}
+	})
+}
+
+func TestBackoff_DryRun(t *testing.T) {
+	t.Run("Does not wait", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(4, 2, time.Hour, 10*time.Hour)
+		b.Timer = timer
+		b.DryRun = true
+
+		var i uint64
+		var last time.Duration
+		for b.Next(context.Background()) {
+			i++
+			_, _, last = b.Status()
+		}
+		for b.Sleep() {
+			i++
+		}
+
+		if i != b.MaxAttempts {
+			t.Errorf("expected number of attempts to be \"%d\", but got \"%d\"", b.MaxAttempts, i)
+		}
+		if len(timer.durations) != 0 {
+			t.Errorf("expected timer to not be started, but it was started \"%d\" times", len(timer.durations))
+		}
+		if last != 8*time.Hour {
+			t.Errorf("expected last delay to be \"%s\", but got \"%s\"", 8*time.Hour, last)
+		}
+	})
+
+	t.Run("Respects context cancellation", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Hour, 10*time.Hour)
+		b.DryRun = true
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if b.Next(ctx) {
+			t.Error("expected Next to return false when the context is cancelled")
+		}
+	})
+}
+
+func TestBackoff_Duration_Precision(t *testing.T) {
+	t.Run("Tiny Min grows monotonically up to Max", func(t *testing.T) {
+		for _, factor := range []float64{1.1, 1.5, 2, 3, 10} {
+			b := backoff.New(0, factor, 1*time.Nanosecond, time.Minute)
+
+			prev := b.Min
+			for i := uint64(1); i <= 1000; i++ {
+				b.NextN(1)
+				d := b.Duration()
+				if d < prev || d > b.Max {
+					t.Fatalf("factor %v, attempt %d: expected duration to be between \"%s\" and \"%s\", but got \"%s\"", factor, i, prev, b.Max, d)
+				}
+				prev = d
+			}
+			if prev != b.Max {
+				t.Errorf("factor %v: expected duration to reach \"%s\", but got \"%s\"", factor, b.Max, prev)
+			}
+		}
+	})
+
+	t.Run("Integer factors are exact", func(t *testing.T) {
+		b := backoff.New(0, 2, 1*time.Nanosecond, time.Duration(1<<52))
+		for i := uint64(1); i <= 52; i++ {
+			b.NextN(1)
+			if d, expect := b.Duration(), time.Duration(1)<<i; d != expect {
+				t.Errorf("Test #%d: expected duration to be \"%d\", but got \"%d\"", i, expect, d)
+			}
+		}
+	})
+
+	t.Run("Rounds to the nearest nanosecond", func(t *testing.T) {
+		// 100 * 1.7^2 is 288.99999999999994 as a float64.
+		b := backoff.New(0, 1.7, 100*time.Nanosecond, time.Second)
+		b.NextN(2)
+		if d, expect := b.Duration(), 289*time.Nanosecond; d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+	})
+}
+
+func TestBackoff_Duration_Misconfigured(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(b *backoff.Backoff)
+		expect time.Duration
+	}{
+		{
+			name:   "Zero Max is unbounded",
+			modify: func(b *backoff.Backoff) { b.Max = 0 },
+			expect: 512 * time.Second,
+		},
+		{
+			name:   "Zero Max saturates",
+			modify: func(b *backoff.Backoff) { b.Max, b.Factor = 0, math.MaxFloat64 },
+			expect: math.MaxInt64,
+		},
+		{
+			name:   "Min greater than Max",
+			modify: func(b *backoff.Backoff) { b.Min, b.Max = time.Minute, time.Second },
+			expect: time.Second,
+		},
+		{
+			name:   "NaN Factor",
+			modify: func(b *backoff.Backoff) { b.Factor = math.NaN() },
+			expect: time.Second,
+		},
+		{
+			name:   "Negative Factor",
+			modify: func(b *backoff.Backoff) { b.Factor = -3 },
+			expect: time.Second,
+		},
+		{
+			name:   "Negative Min",
+			modify: func(b *backoff.Backoff) { b.Min = -time.Second },
+			expect: 0,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := backoff.New(0, 2, time.Second, time.Hour)
+			tc.modify(b)
+			if d := b.DelayAt(9); d != tc.expect {
+				t.Errorf("expected delay to be \"%s\", but got \"%s\"", tc.expect, d)
+			}
+		})
+	}
+}
+
+func FuzzDuration(f *testing.F) {
+	f.Add(uint64(3), 2.0, int64(time.Second), int64(time.Minute), 0.0, 0.0, uint8(0), 0.0, int64(0))
+	f.Add(uint64(1000), 1.5, int64(1), int64(0), 2.0, 0.5, uint8(1), 0.3, int64(time.Millisecond))
+	f.Add(uint64(7), math.NaN(), int64(-1), int64(-1), math.Inf(1), math.NaN(), uint8(2), math.Inf(-1), int64(-1))
+	f.Add(uint64(64), -2.0, int64(time.Hour), int64(time.Second), -1.0, 10.0, uint8(9), 2.0, int64(math.MaxInt64))
+	f.Fuzz(func(t *testing.T, attempt uint64, factor float64, min, max int64, base, factorJitter float64, jitter uint8, jitterFloor float64, jitterAbsolute int64) {
+		b := backoff.New(0, factor, time.Duration(min), time.Duration(max))
+		b.Timer = &mockTimer{}
+		b.Base = base
+		b.FactorJitter = factorJitter
+		b.Jitter = backoff.JitterMode(jitter)
+		b.JitterFloor = jitterFloor
+		b.JitterAbsolute = time.Duration(jitterAbsolute)
+		b.Rand = rand.New(rand.NewSource(int64(attempt)))
+
+		upper := time.Duration(math.MaxInt64)
+		if max > 0 {
+			upper = time.Duration(max)
+		}
+		check := func(name string, d time.Duration) {
+			if d < 0 || d > upper {
+				t.Fatalf("expected %s to be between \"0\" and \"%s\", but got \"%s\"", name, upper, d)
+			}
+		}
+
+		attempt = attempt%1024 + 1
+		check("delay", b.DelayAt(attempt))
+		b.NextN(attempt)
+		check("duration", b.Duration())
+		b.Next(context.Background())
+		_, _, d := b.Status()
+		check("waited delay", d)
+	})
+}
+
+func TestWouldOverflow(t *testing.T) {
+	for i, tc := range []struct {
+		attempt uint64
+		factor  float64
+		min     time.Duration
+		expect  bool
+	}{
+		{attempt: 0, factor: math.MaxFloat64, min: time.Second, expect: false},
+		{attempt: 1, factor: 2, min: time.Second, expect: false},
+		{attempt: 33, factor: 2, min: time.Second, expect: false},
+		{attempt: 34, factor: 2, min: time.Second, expect: true},
+		{attempt: 1, factor: math.MaxFloat64, min: time.Nanosecond, expect: true},
+		{attempt: 1000, factor: 1, min: time.Second, expect: false},
+		{attempt: 0, factor: 1, min: backoff.MaxSafeDuration, expect: false},
+		{attempt: 1, factor: 1.0001, min: backoff.MaxSafeDuration, expect: true},
+	} {
+		if v := backoff.WouldOverflow(tc.attempt, tc.factor, tc.min); v != tc.expect {
+			t.Errorf("Test #%d: expected WouldOverflow to return \"%t\", but got \"%t\"", i+1, tc.expect, v)
+		}
+	}
+
+	// Ensure the schedule saturates at Max once it overflows.
+	b := newBackoffWithMockTimer(0, 2, time.Second, time.Duration(math.MaxInt64))
+	ctx := context.Background()
+	for !backoff.WouldOverflow(b.Attempt(), b.Factor, b.Min) {
+		b.Next(ctx)
+	}
+	if d := b.Duration(); d != b.Max {
+		t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Max, d)
+	}
+}
+
+func TestBackoff_WouldOverflow(t *testing.T) {
+	for i, tc := range []struct {
+		base    float64
+		attempt uint64
+		expect  bool
+	}{
+		{base: 0, attempt: 33, expect: false},
+		{base: 0, attempt: 34, expect: true},
+		{base: 4, attempt: 31, expect: false},
+		{base: 4, attempt: 32, expect: true},
+		{base: 0.25, attempt: 35, expect: false},
+		{base: 0.25, attempt: 36, expect: true},
+	} {
+		b := newBackoffWithMockTimer(0, 2, time.Second, 0)
+		b.Base = tc.base
+		if v := b.WouldOverflow(tc.attempt); v != tc.expect {
+			t.Errorf("Test #%d: expected WouldOverflow to return \"%t\", but got \"%t\"", i+1, tc.expect, v)
+		}
+
+		// The prediction must match whether the delay saturates.
+		b.NextN(tc.attempt)
+		if saturated := b.Duration() == time.Duration(math.MaxInt64); saturated != tc.expect {
+			t.Errorf("Test #%d: expected the delay to be saturated \"%t\", but got \"%s\"", i+1, tc.expect, b.Duration())
+		}
+	}
+
+	b := newBackoffWithMockTimer(0, 2, time.Second, 0)
+	b.Min, b.Max = 0, 0
+	if b.WouldOverflow(100) {
+		t.Error("expected WouldOverflow to return false when retrying immediately")
+	}
+}
+
+func TestMaxSafeDuration(t *testing.T) {
+	// MaxSafeDuration must survive a round trip through a float64.
+	if d := time.Duration(float64(backoff.MaxSafeDuration)); d <= 0 || d > backoff.MaxSafeDuration {
+		t.Errorf("expected \"%d\" to be converted back to at most \"%d\"", d, backoff.MaxSafeDuration)
+	}
+
+	b := newBackoffWithMockTimer(0, 1, backoff.MaxSafeDuration, 0)
+	b.NextN(1)
+	if d := b.Duration(); d <= 0 {
+		t.Errorf("expected duration to be positive, but got \"%s\"", d)
+	}
+}
+
+func TestBackoff_Reset_Sequence(t *testing.T) {
+	timer := &mockTimer{}
+	b := backoff.New(8, 2, 100*time.Millisecond, 5*time.Second)
+	b.Timer = timer
+	b.Jitter = backoff.JitterEqual
+	b.FactorJitter = 0.5
+	b.Adaptive = true
+	b.TargetLatency = 100 * time.Millisecond
+
+	run := func() []time.Duration {
+		timer.durations = nil
+		b.Rand = rand.New(rand.NewSource(1))
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+			b.Observe(time.Duration(b.Attempt()) * 50 * time.Millisecond)
+		}
+		return timer.durations
+	}
+
+	first := run()
+	b.Reset()
+	second := run()
+
+	if len(first) != len(second) {
+		t.Fatalf("expected both sequences to have \"%d\" delays, but got \"%d\"", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, first[i], second[i])
+		}
+	}
+}
+
+func TestBackoff_Next_Allocs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	for _, tc := range []struct {
+		name string
+		b    *backoff.Backoff
+	}{
+		{
+			name: "Zero delay",
+			b:    backoff.New(0, 1, 0, 0),
+		},
+		{
+			name: "Timer",
+			b:    backoff.New(0, 1, time.Nanosecond, time.Nanosecond),
+		},
+	} {
+		// The Timer is created by the warm-up run and reused afterwards.
+		if allocs := testing.AllocsPerRun(100, func() { tc.b.Next(ctx) }); allocs != 0 {
+			t.Errorf("%s: expected Next to not allocate, but got \"%v\" allocations", tc.name, allocs)
+		}
+	}
+}
+
+func BenchmarkBackoff_Next(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("Zero delay", func(b *testing.B) {
+		bo := backoff.New(0, 1, 0, 0)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bo.Next(ctx)
+		}
+	})
+
+	b.Run("Timer", func(b *testing.B) {
+		bo := backoff.New(0, 1, time.Nanosecond, time.Nanosecond)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bo.Next(ctx)
+		}
+	})
+}
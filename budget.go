@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"sync"
+	"time"
+)
+
+// Budget is a token bucket limiting the number of retries made by every
+// Backoff sharing it, to prevent retry storms from overloading whatever is
+// being retried once it starts failing. Every retry takes a token from the
+// bucket, which is refilled at a constant rate. Once the bucket is empty,
+// Backoffs give up immediately instead of retrying, see Backoff.Budget.
+//
+// A Budget is safe for concurrent use. The zero value is ready to use, but has
+// no capacity, so every retry is denied.
+type Budget struct {
+	// Capacity is the max number of tokens held by the bucket, which is the
+	// number of retries that can be made in a burst. The bucket starts full.
+	Capacity float64
+	// Rate is the number of tokens added to the bucket every second, which is
+	// the number of retries that can be sustained every second.
+	Rate float64
+	// Clock is the source of the current time used to refill the bucket. If
+	// nil, time.Now is used.
+	Clock Clock
+
+	mx sync.Mutex
+	// tokens is the number of tokens in the bucket as of last.
+	tokens float64
+	// last is the time the bucket was last refilled, or the zero value if
+	// the bucket has never been used.
+	last time.Time
+}
+
+// NewBudget returns a new Budget with the given capacity, refilled by rate
+// tokens every second.
+func NewBudget(capacity, rate float64) *Budget {
+	return &Budget{
+		Capacity: capacity,
+		Rate:     rate,
+	}
+}
+
+// Allow takes a token from the bucket, returning false if the bucket is empty.
+func (b *Budget) Allow() bool {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Tokens returns the number of tokens in the bucket.
+func (b *Budget) Tokens() float64 {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.refill()
+	return b.tokens
+}
+
+// refill adds the tokens accumulated since the bucket was last refilled.
+func (b *Budget) refill() {
+	now := b.now()
+	if b.last.IsZero() {
+		b.tokens, b.last = b.Capacity, now
+		return
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 && b.Rate > 0 {
+		b.tokens = min(b.tokens+elapsed.Seconds()*b.Rate, b.Capacity)
+	}
+	b.last = now
+}
+
+// now returns the current time from the Budget's Clock, falling back to
+// time.Now if Clock is nil.
+func (b *Budget) now() time.Time {
+	if b.Clock == nil {
+		return realClock{}.Now()
+	}
+	return b.Clock.Now()
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestBudget(t *testing.T) {
+	t.Run("Starts full and refills", func(t *testing.T) {
+		clock := newMockClock()
+		budget := backoff.NewBudget(2, 1)
+		budget.Clock = clock
+
+		for i := 0; i < 2; i++ {
+			if !budget.Allow() {
+				t.Errorf("expected retry #%d to be allowed", i)
+			}
+		}
+		if budget.Allow() {
+			t.Error("expected retry to be denied once the budget is empty")
+		}
+
+		clock.Advance(500 * time.Millisecond)
+		if budget.Allow() {
+			t.Error("expected retry to be denied before a full token was refilled")
+		}
+		clock.Advance(500 * time.Millisecond)
+		if !budget.Allow() {
+			t.Error("expected retry to be allowed once a token was refilled")
+		}
+
+		// Ensure the bucket never holds more than its capacity.
+		clock.Advance(time.Hour)
+		if tokens := budget.Tokens(); tokens != 2 {
+			t.Errorf("expected tokens to be \"%v\", but got \"%v\"", 2, tokens)
+		}
+	})
+
+	t.Run("Zero value denies every retry", func(t *testing.T) {
+		var budget backoff.Budget
+		if budget.Allow() {
+			t.Error("expected retry to be denied")
+		}
+	})
+}
+
+func TestBackoff_Budget(t *testing.T) {
+	budget := backoff.NewBudget(1, 0)
+	b1 := newBackoffWithMockTimer(0, 2, time.Millisecond, time.Second)
+	b1.Budget = budget
+	b2 := b1.Clone()
+
+	// The first attempt never takes a token.
+	for _, b := range []*backoff.Backoff{b1, b2} {
+		if err := b.NextErr(context.Background()); err != nil {
+			t.Errorf("expected the first attempt to not be limited, but got \"%v\"", err)
+		}
+	}
+
+	if err := b1.NextErr(context.Background()); err != nil {
+		t.Errorf("expected the first retry to be allowed, but got \"%v\"", err)
+	}
+	// The Budget is shared with the clone.
+	if err := b2.NextErr(context.Background()); !errors.Is(err, backoff.ErrBudgetExhausted) {
+		t.Errorf("expected error to be \"%v\", but got \"%v\"", backoff.ErrBudgetExhausted, err)
+	}
+	if b2.Attempt() != 1 {
+		t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 1, b2.Attempt())
+	}
+
+	b := backoff.NewWithOptions(0, 2, time.Millisecond, time.Second, backoff.WithBudget(budget))
+	if b.Budget != budget {
+		t.Error("expected WithBudget to set Budget")
+	}
+}
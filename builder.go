@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"time"
+)
+
+// Builder is used to configure a Backoff using chained method calls, as an
+// alternative to New and NewWithOptions.
+//
+//	b, err := backoff.NewBuilder().
+//		MaxAttempts(5).
+//		Min(1 * time.Second).
+//		Max(30 * time.Second).
+//		Jitter(backoff.JitterFull).
+//		Build()
+type Builder struct {
+	maxAttempts uint64
+	factor      float64
+	min         time.Duration
+	max         time.Duration
+	opts        []Option
+}
+
+// NewBuilder returns a new Builder with a Factor of 2.
+func NewBuilder() *Builder {
+	return &Builder{
+		factor: 2,
+	}
+}
+
+// MaxAttempts sets the MaxAttempts of the Backoff.
+func (b *Builder) MaxAttempts(n uint64) *Builder {
+	b.maxAttempts = n
+	return b
+}
+
+// Factor sets the Factor of the Backoff.
+func (b *Builder) Factor(factor float64) *Builder {
+	b.factor = factor
+	return b
+}
+
+// Min sets the Min of the Backoff.
+func (b *Builder) Min(min time.Duration) *Builder {
+	b.min = min
+	return b
+}
+
+// Max sets the Max of the Backoff.
+func (b *Builder) Max(max time.Duration) *Builder {
+	b.max = max
+	return b
+}
+
+// Jitter sets the Jitter of the Backoff, see WithJitter.
+func (b *Builder) Jitter(mode JitterMode) *Builder {
+	return b.With(WithJitter(mode))
+}
+
+// With adds options that are applied to the Backoff in order.
+func (b *Builder) With(opts ...Option) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Build returns a new Backoff using NewWithOptions, or an error if the Backoff
+// fails Validate.
+func (b *Builder) Build() (*Backoff, error) {
+	bo := NewWithOptions(b.maxAttempts, b.factor, b.min, b.max, b.opts...)
+	if err := bo.Validate(); err != nil {
+		return nil, err
+	}
+	return bo, nil
+}
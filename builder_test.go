@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestBuilder(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	b, err := backoff.NewBuilder().
+		MaxAttempts(_maxAttempts).
+		Factor(3).
+		Min(_min).
+		Max(_max).
+		Jitter(backoff.JitterEqual).
+		With(backoff.WithLogger(logger)).
+		Build()
+	if err != nil {
+		t.Fatalf("expected no error, but got \"%v\"", err)
+	}
+
+	for i, tc := range []struct {
+		field  string
+		expect any
+		value  any
+	}{
+		{
+			field:  "MaxAttempts",
+			expect: _maxAttempts,
+			value:  b.MaxAttempts,
+		},
+		{
+			field:  "Factor",
+			expect: float64(3),
+			value:  b.Factor,
+		},
+		{
+			field:  "Min",
+			expect: _min,
+			value:  b.Min,
+		},
+		{
+			field:  "Max",
+			expect: _max,
+			value:  b.Max,
+		},
+		{
+			field:  "Jitter",
+			expect: backoff.JitterEqual,
+			value:  b.Jitter,
+		},
+		{
+			field:  "Logger",
+			expect: logger,
+			value:  b.Logger,
+		},
+	} {
+		if tc.expect != tc.value {
+			t.Errorf("Test #%d: expected %s to be \"%v\", but got \"%v\"", i+1, tc.field, tc.expect, tc.value)
+		}
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		b, err := backoff.NewBuilder().Max(time.Second).Build()
+		if err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		if b.Factor != 2 {
+			t.Errorf("expected Factor to be \"%v\", but got \"%v\"", 2, b.Factor)
+		}
+	})
+
+	t.Run("Validates", func(t *testing.T) {
+		if _, err := backoff.NewBuilder().Min(_max).Max(_min).Build(); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"time"
+)
+
+// Clock is used as an abstraction to swap out the source of the current time
+// used by Backoff, for example to track MaxElapsed. Most users will not need
+// to implement this interface, it is used for mocking during tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// realClock implements the Clock interface using time.Now.
+type realClock struct{}
+
+var _ Clock = realClock{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// now returns the current time from the Backoff's Clock, falling back to
+// time.Now if Clock is nil.
+func (b *Backoff) now() time.Time {
+	if b.Clock == nil {
+		return realClock{}.Now()
+	}
+	return b.Clock.Now()
+}
+
+// NextTime returns the time the next attempt would start at if Next was called
+// at the given time, which is now plus Duration.
+func (b *Backoff) NextTime(now time.Time) time.Time {
+	return now.Add(b.Duration())
+}
+
+// NextTimeNow behaves like NextTime, using the current time from Clock.
+func (b *Backoff) NextTimeNow() time.Time {
+	return b.NextTime(b.now())
+}
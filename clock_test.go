@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+// mockClock implements backoff.Clock by returning a time that only changes
+// when Advance is called.
+type mockClock struct {
+	now time.Time
+}
+
+var _ backoff.Clock = (*mockClock)(nil)
+
+func newMockClock() *mockClock {
+	return &mockClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *mockClock) Now() time.Time {
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *mockClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// clockTimer fires as soon as it is started, after advancing clock by the
+// duration it was started with, like a real timer would.
+type clockTimer struct {
+	backoff.Timer
+	clock *mockClock
+}
+
+func newClockTimer(clock *mockClock) *clockTimer {
+	return &clockTimer{Timer: backoff.NewSynchronousTimer(), clock: clock}
+}
+
+func (t *clockTimer) Start(d time.Duration) {
+	t.clock.Advance(d)
+	t.Timer.Start(d)
+}
+
+func TestBackoff_Clock(t *testing.T) {
+	clock := newMockClock()
+	b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+	b.Clock = clock
+	b.ResetAfter = time.Hour
+
+	ctx := context.Background()
+	b.Next(ctx)
+	b.Next(ctx)
+	b.Succeeded()
+
+	clock.Advance(time.Hour)
+	b.Next(ctx)
+	if b.Attempt() != 1 {
+		t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 1, b.Attempt())
+	}
+}
+
+func TestBackoff_NextTime(t *testing.T) {
+	clock := newMockClock()
+	b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+	b.Clock = clock
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if next := b.NextTime(now); !next.Equal(now) {
+		t.Errorf("expected next time to be \"%s\", but got \"%s\"", now, next)
+	}
+
+	b.Next(context.Background())
+	if next, expect := b.NextTime(now), now.Add(2*time.Second); !next.Equal(expect) {
+		t.Errorf("expected next time to be \"%s\", but got \"%s\"", expect, next)
+	}
+	if next, expect := b.NextTimeNow(), clock.Now().Add(2*time.Second); !next.Equal(expect) {
+		t.Errorf("expected next time to be \"%s\", but got \"%s\"", expect, next)
+	}
+}
+
+// driftTimer implements backoff.Timer by firing immediately and advancing the
+// clock by the duration it was started with plus late.
+type driftTimer struct {
+	backoff.FakeTimer
+	clock *mockClock
+	late  time.Duration
+}
+
+func (t *driftTimer) Start(d time.Duration) {
+	t.clock.Advance(d + t.late)
+	t.FakeTimer.Start(d)
+}
+
+func TestBackoff_OnDrift(t *testing.T) {
+	clock := newMockClock()
+	b := backoff.New(3, 2, 1*time.Second, 5*time.Second)
+	b.Clock = clock
+	b.Timer = &driftTimer{clock: clock, late: 5 * time.Millisecond}
+
+	type drift struct{ requested, actual time.Duration }
+	var drifts []drift
+	b.OnDrift = func(requested, actual time.Duration) {
+		drifts = append(drifts, drift{requested, actual})
+	}
+
+	for b.Next(context.Background()) {
+	}
+
+	// The first attempt is not delayed, so the Timer is not used.
+	expect := []drift{
+		{2 * time.Second, 2*time.Second + 5*time.Millisecond},
+		{4 * time.Second, 4*time.Second + 5*time.Millisecond},
+	}
+	if len(drifts) != len(expect) {
+		t.Fatalf("expected OnDrift to be called \"%d\" times, but got \"%d\"", len(expect), len(drifts))
+	}
+	for i, d := range drifts {
+		if d != expect[i] {
+			t.Errorf("Test #%d: expected drift to be %v, but got %v", i+1, expect[i], d)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+// WithDefaults returns a clone of the Backoff, see Clone, where every
+// configuration field that is set to its zero value is set to the value of
+// the same field of def instead. This allows layering a partial configuration
+// on top of a default one.
+//
+// Every exported field takes part except Timer, which is handled like Clone
+// does. As a zero value always means the field is inherited, a field cannot be
+// overridden with its zero value, even where the zero value has a meaning of
+// its own. For example, if def limits MaxAttempts, the returned Backoff is
+// limited too, and if def sets a bool like DryRun, it is set on the returned
+// Backoff as well. Such fields must be changed on the returned Backoff.
+//
+// If def is nil, the returned Backoff is a clone of b.
+func (b *Backoff) WithDefaults(def *Backoff) *Backoff {
+	c := b.Clone()
+	if def == nil {
+		return c
+	}
+
+	c.MaxAttempts = orDefault(c.MaxAttempts, def.MaxAttempts)
+	c.Factor = orDefault(c.Factor, def.Factor)
+	c.Base = orDefault(c.Base, def.Base)
+	c.Min = orDefault(c.Min, def.Min)
+	c.Max = orDefault(c.Max, def.Max)
+	c.HardMax = orDefault(c.HardMax, def.HardMax)
+	c.NoMinClamp = orDefault(c.NoMinClamp, def.NoMinClamp)
+	c.Strategy = orDefault(c.Strategy, def.Strategy)
+	c.MaxElapsed = orDefault(c.MaxElapsed, def.MaxElapsed)
+	c.MaxCappedWaits = orDefault(c.MaxCappedWaits, def.MaxCappedWaits)
+	c.MinRemainingForRetry = orDefault(c.MinRemainingForRetry, def.MinRemainingForRetry)
+	c.Budget = orDefault(c.Budget, def.Budget)
+	c.InitialDelay = orDefault(c.InitialDelay, def.InitialDelay)
+	c.DelayFirst = orDefault(c.DelayFirst, def.DelayFirst)
+	c.Jitter = orDefault(c.Jitter, def.Jitter)
+	c.JitterFloor = orDefault(c.JitterFloor, def.JitterFloor)
+	c.JitterAbsolute = orDefault(c.JitterAbsolute, def.JitterAbsolute)
+	c.FactorJitter = orDefault(c.FactorJitter, def.FactorJitter)
+	c.RecordHistory = orDefault(c.RecordHistory, def.RecordHistory)
+	c.Rand = orDefault(c.Rand, def.Rand)
+	c.Round = orDefault(c.Round, def.Round)
+	c.ResetAfter = orDefault(c.ResetAfter, def.ResetAfter)
+	c.Adaptive = orDefault(c.Adaptive, def.Adaptive)
+	c.TargetLatency = orDefault(c.TargetLatency, def.TargetLatency)
+	c.AttemptTimeout = orDefault(c.AttemptTimeout, def.AttemptTimeout)
+	c.SubtractWork = orDefault(c.SubtractWork, def.SubtractWork)
+	c.Logger = orDefault(c.Logger, def.Logger)
+	c.Clock = orDefault(c.Clock, def.Clock)
+	c.DryRun = orDefault(c.DryRun, def.DryRun)
+
+	// Funcs are not comparable.
+	if c.OnSaturate == nil {
+		c.OnSaturate = def.OnSaturate
+	}
+	if c.OnAttempt == nil {
+		c.OnAttempt = def.OnAttempt
+	}
+	if c.OnWait == nil {
+		c.OnWait = def.OnWait
+	}
+	if c.OnGiveUp == nil {
+		c.OnGiveUp = def.OnGiveUp
+	}
+	if c.OnDrift == nil {
+		c.OnDrift = def.OnDrift
+	}
+	if c.NewTimer == nil {
+		c.NewTimer = def.NewTimer
+	}
+	return c
+}
+
+// orDefault returns v, or def if v is the zero value.
+func orDefault[T comparable](v, def T) T {
+	var zero T
+	if v == zero {
+		return def
+	}
+	return v
+}
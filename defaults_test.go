@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestBackoff_WithDefaults(t *testing.T) {
+	def := backoff.New(5, 2, 1*time.Second, time.Minute)
+	def.Jitter = backoff.JitterFull
+	def.ResetAfter = time.Hour
+	def.OnWait = func(time.Duration) {}
+
+	override := &backoff.Backoff{
+		Min:    2 * time.Second,
+		Round:  time.Second,
+		DryRun: true,
+		Factor: 0,
+		Timer:  &mockTimer{},
+		Jitter: backoff.JitterNone,
+	}
+	override.NextN(2)
+
+	b := override.WithDefaults(def)
+	if b == override || b == def {
+		t.Fatal("expected WithDefaults to return a new Backoff")
+	}
+	for i, tc := range []struct {
+		field  string
+		expect any
+		value  any
+	}{
+		{field: "MaxAttempts", expect: uint64(5), value: b.MaxAttempts},
+		{field: "Factor", expect: float64(2), value: b.Factor},
+		{field: "Min", expect: 2 * time.Second, value: b.Min},
+		{field: "Max", expect: time.Minute, value: b.Max},
+		{field: "Round", expect: time.Second, value: b.Round},
+		{field: "Jitter", expect: backoff.JitterFull, value: b.Jitter},
+		{field: "ResetAfter", expect: time.Hour, value: b.ResetAfter},
+		{field: "DryRun", expect: true, value: b.DryRun},
+		{field: "Attempt", expect: uint64(0), value: b.Attempt()},
+	} {
+		if tc.expect != tc.value {
+			t.Errorf("Test #%d: expected %s to be \"%v\", but got \"%v\"", i+1, tc.field, tc.expect, tc.value)
+		}
+	}
+	if b.OnWait == nil {
+		t.Error("expected OnWait to be inherited")
+	}
+	if b.Timer != override.Timer {
+		t.Error("expected the Timer to be handled like Clone")
+	}
+	if err := b.Validate(); err != nil {
+		t.Errorf("expected no error, but got \"%v\"", err)
+	}
+
+	// A Strategy that is not comparable must not panic.
+	override.Strategy = backoff.Chain(backoff.New(1, 1, 0, 0)).Strategy
+	if b := override.WithDefaults(def); b.Strategy == nil {
+		t.Error("expected Strategy to be kept")
+	}
+
+	if b := override.WithDefaults(nil); b.Factor != 0 {
+		t.Errorf("expected a nil default to not change any field, but got Factor \"%v\"", b.Factor)
+	}
+}
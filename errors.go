@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMaxAttempts is returned when the backoff gave up because the
+	// MaxAttempts limit was reached.
+	ErrMaxAttempts = errors.New("backoff: max attempts reached")
+	// ErrMaxElapsed is returned when the backoff gave up because the
+	// MaxElapsed limit was reached.
+	ErrMaxElapsed = errors.New("backoff: max elapsed time reached")
+	// ErrMaxCappedWaits is returned when the backoff gave up because the
+	// MaxCappedWaits limit was reached.
+	ErrMaxCappedWaits = errors.New("backoff: max capped waits reached")
+	// ErrDeadline is returned when the backoff gave up because the time left
+	// before the context's deadline after waiting would be less than
+	// MinRemainingForRetry.
+	ErrDeadline = errors.New("backoff: not enough time left before the deadline")
+	// ErrBudgetExhausted is returned when the backoff gave up because its
+	// Budget did not allow another retry.
+	ErrBudgetExhausted = errors.New("backoff: retry budget exhausted")
+)
+
+// GaveUpError is returned by Retry when the backoff gave up because a limit
+// was reached, describing the attempts that were made. It can be extracted
+// using errors.As:
+//
+//	var gerr *backoff.GaveUpError
+//	if errors.As(err, &gerr) {
+//		slog.Warn("giving up", "attempts", gerr.Attempts, "elapsed", gerr.Elapsed)
+//	}
+//
+// Unwrap returns Last, so the last error can be matched using errors.Is, as
+// can Reason.
+type GaveUpError struct {
+	// Attempts is the number of attempts that were made.
+	Attempts uint64
+	// Elapsed is the time since the first attempt.
+	Elapsed time.Duration
+	// Reason is the sentinel error describing the limit that was reached,
+	// either ErrMaxAttempts, ErrMaxElapsed, ErrMaxCappedWaits, ErrDeadline or
+	// ErrBudgetExhausted.
+	Reason error
+	// Last is the last error returned by the operation, if any.
+	Last error
+}
+
+var _ error = (*GaveUpError)(nil)
+
+// Error returns the message of Reason, followed by the message of Last if it
+// is set. If Reason is nil, "backoff: gave up" is used instead.
+func (e *GaveUpError) Error() string {
+	msg := "backoff: gave up"
+	if e.Reason != nil {
+		msg = e.Reason.Error()
+	}
+	if e.Last == nil {
+		return msg
+	}
+	return msg + ": " + e.Last.Error()
+}
+
+func (e *GaveUpError) Unwrap() error {
+	return e.Last
+}
+
+// Is reports whether target is Reason, so errors.Is matches the sentinel
+// error even though Unwrap returns Last.
+func (e *GaveUpError) Is(target error) bool {
+	return e.Reason != nil && target == e.Reason
+}
+
+// giveUp returns a GaveUpError wrapping the last error returned by an
+// operation with the sentinel error describing why the backoff gave up.
+func (b *Backoff) giveUp(reason, err error) error {
+	var elapsed time.Duration
+	if !b.start.IsZero() {
+		elapsed = b.now().Sub(b.start)
+	}
+	return &GaveUpError{
+		Attempts: b.n,
+		Elapsed:  elapsed,
+		Reason:   reason,
+		Last:     err,
+	}
+}
+
+// PermanentError wraps an error to signal that the operation that returned it
+// should not be retried.
+type PermanentError struct {
+	Err error
+}
+
+var _ error = (*PermanentError)(nil)
+
+// Permanent wraps the given error in a PermanentError. If err is nil, nil is
+// returned.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// asPermanent returns the PermanentError in err's tree, if any.
+func asPermanent(err error) (*PermanentError, bool) {
+	var perr *PermanentError
+	if errors.As(err, &perr) {
+		return perr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestPermanent(t *testing.T) {
+	if backoff.Permanent(nil) != nil {
+		t.Error("expected Permanent(nil) to return nil")
+		return
+	}
+
+	err := errors.New("permanent")
+	perr := backoff.Permanent(err)
+	if perr.Error() != err.Error() {
+		t.Errorf("expected error message to be \"%s\", but got \"%s\"", err.Error(), perr.Error())
+	}
+	if !errors.Is(perr, err) {
+		t.Error("expected Permanent to wrap the given error")
+	}
+
+	var target *backoff.PermanentError
+	if !errors.As(fmt.Errorf("wrapped: %w", perr), &target) {
+		t.Error("expected a wrapped PermanentError to be found by errors.As")
+	}
+}
+
+func TestGaveUpError(t *testing.T) {
+	last := errors.New("connection refused")
+	err := error(&backoff.GaveUpError{
+		Attempts: 3,
+		Elapsed:  time.Second,
+		Reason:   backoff.ErrMaxAttempts,
+		Last:     last,
+	})
+
+	if msg := "backoff: max attempts reached: connection refused"; err.Error() != msg {
+		t.Errorf("expected error message to be \"%s\", but got \"%s\"", msg, err.Error())
+	}
+	if !errors.Is(err, last) || !errors.Is(err, backoff.ErrMaxAttempts) {
+		t.Error("expected GaveUpError to match both Last and Reason")
+	}
+	if errors.Is(err, backoff.ErrMaxElapsed) {
+		t.Error("expected GaveUpError to not match another sentinel")
+	}
+	if errors.Unwrap(err) != last {
+		t.Error("expected Unwrap to return Last")
+	}
+
+	err = &backoff.GaveUpError{Reason: backoff.ErrMaxAttempts}
+	if err.Error() != backoff.ErrMaxAttempts.Error() {
+		t.Errorf("expected error message to be \"%s\", but got \"%s\"", backoff.ErrMaxAttempts.Error(), err.Error())
+	}
+
+	for i, tc := range []struct {
+		err    *backoff.GaveUpError
+		expect string
+	}{
+		{err: &backoff.GaveUpError{}, expect: "backoff: gave up"},
+		{err: &backoff.GaveUpError{Last: last}, expect: "backoff: gave up: connection refused"},
+	} {
+		if msg := tc.err.Error(); msg != tc.expect {
+			t.Errorf("Test #%d: expected error message to be \"%s\", but got \"%s\"", i+1, tc.expect, msg)
+		}
+		if errors.Is(tc.err, nil) {
+			t.Errorf("Test #%d: expected GaveUpError to not match nil", i+1)
+		}
+	}
+}
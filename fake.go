@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeTimer implements the Timer interface by firing immediately every time
+// Start is called, recording the durations it was started with. It is
+// intended to be used when testing code that uses a Backoff, so tests do not
+// have to wait for any delays.
+//
+// The zero value is ready to use.
+type FakeTimer struct {
+	mx        sync.Mutex
+	c         chan time.Time
+	durations []time.Duration
+}
+
+var _ Timer = (*FakeTimer)(nil)
+
+// NewForTest returns a Backoff using a FakeTimer with small delays, along
+// with the FakeTimer so the delays the Backoff waited for can be inspected.
+//
+//	b, timer := backoff.NewForTest()
+//	b.MaxAttempts = 3
+//	// Run the code under test using b.
+//	if d := timer.Durations(); len(d) != 2 {
+//		t.Errorf("expected two retries, but got %d", len(d))
+//	}
+func NewForTest() (*Backoff, *FakeTimer) {
+	t := &FakeTimer{}
+	b := New(10, 2, time.Millisecond, time.Second)
+	b.Timer = t
+	return b, t
+}
+
+func (t *FakeTimer) C() <-chan time.Time {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return t.c
+}
+
+func (t *FakeTimer) Start(d time.Duration) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	t.durations = append(t.durations, d)
+	if t.c == nil {
+		t.c = make(chan time.Time, 1)
+	}
+	select {
+	case t.c <- time.Now():
+	default:
+	}
+}
+
+func (t *FakeTimer) Stop() bool {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	// Drain the value sent by Start, so it is never received after Stop.
+	select {
+	case <-t.c:
+	default:
+	}
+	return true
+}
+
+// Durations returns every duration the timer was started with, in order.
+func (t *FakeTimer) Durations() []time.Duration {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return append([]time.Duration(nil), t.durations...)
+}
+
+// synchronousTimer implements the Timer interface by sending to its channel
+// inside of Start, on the goroutine that started it.
+type synchronousTimer struct {
+	c chan time.Time
+}
+
+var _ Timer = (*synchronousTimer)(nil)
+
+// NewSynchronousTimer returns a Timer that fires inside of Start, so a value
+// can be received from C as soon as Start returns, without depending on any
+// other goroutine being scheduled. Unlike FakeTimer, it does not record the
+// durations it was started with.
+//
+// It is intended for tests that want Next to go through the Timer without
+// waiting. The returned Timer is not safe for concurrent use.
+func NewSynchronousTimer() Timer {
+	return &synchronousTimer{c: make(chan time.Time, 1)}
+}
+
+func (t *synchronousTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *synchronousTimer) Start(time.Duration) {
+	select {
+	case t.c <- time.Now():
+	default:
+	}
+}
+
+func (t *synchronousTimer) Stop() bool {
+	// Drain the value sent by Start, so it is never received after Stop.
+	select {
+	case <-t.c:
+	default:
+	}
+	return true
+}
+
+// noopTimer implements the Timer interface by never waiting, its channel is
+// closed so receiving from it always succeeds immediately.
+type noopTimer struct{}
+
+var _ Timer = noopTimer{}
+
+// closedChan is the channel returned by noopTimer.C.
+var closedChan = func() chan time.Time {
+	c := make(chan time.Time)
+	close(c)
+	return c
+}()
+
+// NewNoopTimer returns a Timer that never waits, which can be used to disable
+// waiting for a specific Backoff. Unlike DryRun, the Timer is still used, and
+// unlike NewSynchronousTimer, it has no state, so it is safe for concurrent
+// use and can be shared between clones.
+func NewNoopTimer() Timer {
+	return noopTimer{}
+}
+
+func (noopTimer) C() <-chan time.Time {
+	return closedChan
+}
+
+func (noopTimer) Start(time.Duration) {}
+
+func (noopTimer) Stop() bool {
+	return true
+}
+
+// DurationsEqual reports whether a and b differ by at most tolerance. It is
+// intended for tests comparing delays that cannot be exact, like jittered
+// delays or the time a real Timer took to fire.
+func DurationsEqual(a, b, tolerance time.Duration) bool {
+	if a < b {
+		a, b = b, a
+	}
+	if tolerance < 0 {
+		return false
+	}
+	// The difference is computed unsigned, so it cannot overflow.
+	return uint64(a)-uint64(b) <= uint64(tolerance)
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestNewForTest(t *testing.T) {
+	b, timer := backoff.NewForTest()
+	b.MaxAttempts = 4
+
+	fn, _ := failN(3)
+	err := b.Retry(context.Background(), fn)
+	if err != nil {
+		t.Fatalf("expected no error, but got \"%v\"", err)
+	}
+
+	expect := []time.Duration{2 * time.Millisecond, 4 * time.Millisecond, 8 * time.Millisecond}
+	durations := timer.Durations()
+	if len(durations) != len(expect) {
+		t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(durations))
+	}
+	for i, d := range durations {
+		if d != expect[i] {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+		}
+	}
+}
+
+func TestFakeTimer_Stop(t *testing.T) {
+	timer := &backoff.FakeTimer{}
+	timer.Start(time.Second)
+	if !timer.Stop() {
+		t.Fatal("expected Stop to return true")
+	}
+
+	select {
+	case <-timer.C():
+		t.Error("expected no value to be received after Stop")
+	default:
+	}
+}
+
+func TestNewSynchronousTimer(t *testing.T) {
+	timer := backoff.NewSynchronousTimer()
+	timer.Start(time.Hour)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatal("expected a value to be ready as soon as Start returns")
+	}
+
+	timer.Start(time.Hour)
+	if !timer.Stop() {
+		t.Fatal("expected Stop to return true")
+	}
+	select {
+	case <-timer.C():
+		t.Error("expected no value to be received after Stop")
+	default:
+	}
+
+	b := backoff.New(4, 2, time.Hour, time.Hour)
+	b.Timer = timer
+	var attempts int
+	for b.Next(context.Background()) {
+		attempts++
+	}
+	if attempts != 4 {
+		t.Errorf("expected \"%d\" attempts, but got \"%d\"", 4, attempts)
+	}
+}
+
+func TestNewNoopTimer(t *testing.T) {
+	timer := backoff.NewNoopTimer()
+	for i := 0; i < 2; i++ {
+		timer.Start(time.Hour)
+		select {
+		case <-timer.C():
+		default:
+			t.Fatalf("Test #%d: expected a value to be ready as soon as Start returns", i+1)
+		}
+	}
+	if !timer.Stop() {
+		t.Error("expected Stop to return true")
+	}
+
+	b := backoff.New(4, 2, time.Hour, time.Hour)
+	b.Timer = timer
+	c := b.Clone()
+
+	for _, b := range []*backoff.Backoff{b, c} {
+		var attempts int
+		for b.Next(context.Background()) {
+			attempts++
+		}
+		if attempts != 4 {
+			t.Errorf("expected \"%d\" attempts, but got \"%d\"", 4, attempts)
+		}
+	}
+}
+
+func TestDurationsEqual(t *testing.T) {
+	for i, tc := range []struct {
+		a, b, tolerance time.Duration
+		expect          bool
+	}{
+		{a: time.Second, b: time.Second, tolerance: 0, expect: true},
+		{a: time.Second, b: 990 * time.Millisecond, tolerance: 10 * time.Millisecond, expect: true},
+		{a: 990 * time.Millisecond, b: time.Second, tolerance: 10 * time.Millisecond, expect: true},
+		{a: 989 * time.Millisecond, b: time.Second, tolerance: 10 * time.Millisecond, expect: false},
+		{a: time.Second, b: time.Second, tolerance: -1, expect: false},
+		{a: math.MinInt64, b: 0, tolerance: math.MaxInt64, expect: false},
+		{a: math.MaxInt64, b: math.MinInt64, tolerance: math.MaxInt64, expect: false},
+		{a: math.MaxInt64, b: 0, tolerance: math.MaxInt64, expect: true},
+	} {
+		if equal := backoff.DurationsEqual(tc.a, tc.b, tc.tolerance); equal != tc.expect {
+			t.Errorf("Test #%d: expected DurationsEqual(%s, %s, %s) to be \"%t\", but got \"%t\"", i+1, tc.a, tc.b, tc.tolerance, tc.expect, equal)
+		}
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/matthewpi/backoff
 
-go 1.21
+go 1.22
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Group holds a Backoff for every key, for example to back off separately
+// for every client IP. A Group is safe for concurrent use, but the Backoffs
+// it returns are not, every Backoff must only be used by one goroutine at a
+// time.
+//
+// To bound memory usage, keys can be limited using MaxKeys and TTL. Removing
+// a key from the Group does not affect a Backoff that is still in use, the
+// next call to Get for the key starts a new one, at the first attempt.
+//
+// The zero value is ready to use and starts Backoffs using Default.
+type Group[K comparable] struct {
+	// Policy is used to start the Backoff for a new key.
+	Policy Policy
+	// MaxKeys is the max number of keys held by the Group. Once reached, the
+	// least recently used key whose Backoff is idle, having made no attempt
+	// since it was started or last reset, is removed when a new key is added.
+	// If every Backoff is in the middle of a sequence, the least recently
+	// used key is removed as a last resort. If set to 0, the number of keys
+	// will not be limited.
+	MaxKeys int
+	// TTL is how long a key is held after it was last passed to Get. If set
+	// to 0, keys are held until they are removed by MaxKeys or Forget.
+	TTL time.Duration
+	// Clock is the source of the current time used for TTL. If nil, time.Now
+	// is used.
+	Clock Clock
+
+	mx sync.Mutex
+	// keys maps every key to its element in lru.
+	keys map[K]*list.Element
+	// lru holds the entries of every key, starting with the most recently
+	// used.
+	lru *list.List
+}
+
+// groupEntry is the value of every element in Group.lru.
+type groupEntry[K comparable] struct {
+	key K
+	b   *Backoff
+	// used is the time the key was last passed to Get.
+	used time.Time
+}
+
+// Get returns the Backoff for the given key, starting a new one using Policy
+// if the key is not in the Group.
+func (g *Group[K]) Get(key K) *Backoff {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	now := g.now()
+	g.expire(now)
+
+	if el, ok := g.keys[key]; ok {
+		e := el.Value.(*groupEntry[K])
+		e.used = now
+		g.lru.MoveToFront(el)
+		return e.b
+	}
+
+	if g.keys == nil {
+		g.keys = make(map[K]*list.Element)
+		g.lru = list.New()
+	}
+	if g.MaxKeys > 0 {
+		for g.lru.Len() >= g.MaxKeys {
+			g.remove(g.evictee())
+		}
+	}
+
+	e := &groupEntry[K]{key: key, b: g.Policy.Start(), used: now}
+	g.keys[key] = g.lru.PushFront(e)
+	return e.b
+}
+
+// Forget removes the given key from the Group, which should be done once the
+// operation for the key succeeded.
+func (g *Group[K]) Forget(key K) {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	if el, ok := g.keys[key]; ok {
+		g.remove(el)
+	}
+}
+
+// Len returns the number of keys held by the Group.
+func (g *Group[K]) Len() int {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	g.expire(g.now())
+	if g.lru == nil {
+		return 0
+	}
+	return g.lru.Len()
+}
+
+// expire removes every key that was not used within the TTL.
+func (g *Group[K]) expire(now time.Time) {
+	if g.TTL <= 0 || g.lru == nil {
+		return
+	}
+	for el := g.lru.Back(); el != nil; el = g.lru.Back() {
+		if now.Sub(el.Value.(*groupEntry[K]).used) < g.TTL {
+			return
+		}
+		g.remove(el)
+	}
+}
+
+// evictee returns the element to remove once MaxKeys is reached, which is
+// the least recently used one with an idle Backoff, or the least recently
+// used one if there is none.
+func (g *Group[K]) evictee() *list.Element {
+	for el := g.lru.Back(); el != nil; el = el.Prev() {
+		if el.Value.(*groupEntry[K]).b.idle() {
+			return el
+		}
+	}
+	return g.lru.Back()
+}
+
+// remove removes the given element from the Group.
+func (g *Group[K]) remove(el *list.Element) {
+	delete(g.keys, el.Value.(*groupEntry[K]).key)
+	g.lru.Remove(el)
+}
+
+// now returns the current time from the Group's Clock, falling back to
+// time.Now if Clock is nil.
+func (g *Group[K]) now() time.Time {
+	if g.Clock == nil {
+		return realClock{}.Now()
+	}
+	return g.Clock.Now()
+}
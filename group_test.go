@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("Returns the same Backoff for a key", func(t *testing.T) {
+		var g backoff.Group[string]
+
+		a := g.Get("a")
+		if g.Get("a") != a {
+			t.Error("expected the same backoff to be returned for the same key")
+		}
+		if g.Get("b") == a {
+			t.Error("expected a different backoff to be returned for a different key")
+		}
+		if g.Len() != 2 {
+			t.Errorf("expected length to be \"%d\", but got \"%d\"", 2, g.Len())
+		}
+	})
+
+	t.Run("Uses Policy", func(t *testing.T) {
+		g := backoff.Group[string]{Policy: backoff.New(7, 2, time.Second, time.Minute).Policy()}
+		if b := g.Get("a"); b.MaxAttempts != 7 {
+			t.Errorf("expected max attempts to be \"%d\", but got \"%d\"", 7, b.MaxAttempts)
+		}
+	})
+
+	t.Run("Forget removes a key", func(t *testing.T) {
+		var g backoff.Group[string]
+
+		a := g.Get("a")
+		g.Forget("a")
+		g.Forget("unknown")
+		if g.Len() != 0 {
+			t.Errorf("expected length to be \"%d\", but got \"%d\"", 0, g.Len())
+		}
+		if g.Get("a") == a {
+			t.Error("expected a new backoff after Forget")
+		}
+	})
+
+	t.Run("MaxKeys evicts the least recently used key", func(t *testing.T) {
+		g := backoff.Group[int]{MaxKeys: 3}
+
+		first := g.Get(1)
+		g.Get(2)
+		g.Get(3)
+		g.Get(1)
+		g.Get(4)
+
+		if g.Len() != 3 {
+			t.Errorf("expected length to be \"%d\", but got \"%d\"", 3, g.Len())
+		}
+		if g.Get(1) != first {
+			t.Error("expected the recently used key to be kept")
+		}
+		if g.Len() != 3 {
+			t.Errorf("expected length to be \"%d\", but got \"%d\"", 3, g.Len())
+		}
+	})
+
+	t.Run("MaxKeys keeps keys in the middle of a sequence", func(t *testing.T) {
+		g := backoff.Group[int]{MaxKeys: 3}
+
+		first := g.Get(1)
+		first.NextN(2)
+		g.Get(2)
+		g.Get(3)
+		g.Get(4)
+
+		if g.Get(1) != first {
+			t.Error("expected the key in the middle of a sequence to be kept")
+		}
+		if first.Attempt() != 2 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, first.Attempt())
+		}
+		if g.Len() != 3 {
+			t.Errorf("expected length to be \"%d\", but got \"%d\"", 3, g.Len())
+		}
+
+		// Once reset, the key is idle again.
+		first.Reset()
+		g.Get(3)
+		g.Get(4)
+		g.Get(5)
+		if g.Get(1) == first {
+			t.Error("expected the idle key to be evicted")
+		}
+	})
+
+	t.Run("MaxKeys evicts active keys as a last resort", func(t *testing.T) {
+		g := backoff.Group[int]{MaxKeys: 2}
+
+		first := g.Get(1)
+		first.NextN(1)
+		g.Get(2).NextN(1)
+		g.Get(3)
+
+		if g.Len() != 2 {
+			t.Errorf("expected length to be \"%d\", but got \"%d\"", 2, g.Len())
+		}
+		if g.Get(1) == first {
+			t.Error("expected the least recently used key to be evicted")
+		}
+	})
+
+	t.Run("TTL evicts idle keys", func(t *testing.T) {
+		clock := newMockClock()
+		g := backoff.Group[string]{TTL: time.Minute, Clock: clock}
+
+		a := g.Get("a")
+		clock.Advance(30 * time.Second)
+		g.Get("b")
+		clock.Advance(30 * time.Second)
+
+		if g.Len() != 1 {
+			t.Errorf("expected length to be \"%d\", but got \"%d\"", 1, g.Len())
+		}
+		if g.Get("a") == a {
+			t.Error("expected a new backoff once the TTL has passed")
+		}
+	})
+
+	t.Run("Is safe for concurrent use", func(t *testing.T) {
+		g := backoff.Group[string]{MaxKeys: 16}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					// Every Backoff is only used by one goroutine, while
+					// others may check whether it is idle.
+					g.Get(fmt.Sprintf("%d-%d", i, j%20)).NextN(1)
+					if j%10 == 0 {
+						g.Forget(fmt.Sprintf("%d-%d", i, j%20))
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if g.Len() > g.MaxKeys {
+			t.Errorf("expected length to be at most \"%d\", but got \"%d\"", g.MaxKeys, g.Len())
+		}
+	})
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+	"errors"
+)
+
+// RaceWithResult hedges calls to fn, returning the result of the first call
+// that succeeds. The first call starts immediately, then every time the
+// backoff's delay passes without a call succeeding, another call is started
+// alongside the calls that are still running. Once a call succeeds, the
+// context passed to the other calls is cancelled.
+//
+// The number of calls is limited by MaxAttempts, if MaxAttempts is 0 calls
+// will be started until one succeeds or the context is cancelled.
+//
+// If a call returns a PermanentError, the error wrapped by it is returned
+// immediately. If every call fails, the last error is returned. If the context
+// is cancelled, the cause of its cancellation is returned joined with the last
+// error.
+func RaceWithResult[T any](ctx context.Context, b *Backoff, fn func(context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	ticks := b.Ticks(ctx)
+	defer func() {
+		cancel()
+		// Wait for Ticks to stop using the Backoff.
+		for range ticks {
+		}
+	}()
+
+	type result struct {
+		v   T
+		err error
+	}
+	results := make(chan result)
+
+	var (
+		zero    T
+		last    error
+		running int
+	)
+	for c := ticks; c != nil || running > 0; {
+		select {
+		case <-ctx.Done():
+			return zero, errors.Join(context.Cause(ctx), last)
+		case _, ok := <-c:
+			if !ok {
+				c = nil
+				continue
+			}
+
+			running++
+			go func() {
+				v, err := fn(ctx)
+				select {
+				case <-ctx.Done():
+				case results <- result{v: v, err: err}:
+				}
+			}()
+		case r := <-results:
+			running--
+			if r.err == nil {
+				return r.v, nil
+			}
+			if perr, ok := asPermanent(r.err); ok {
+				return zero, perr.Err
+			}
+			last = r.err
+		}
+	}
+
+	if err := context.Cause(ctx); err != nil {
+		return zero, errors.Join(err, last)
+	}
+	return zero, last
+}
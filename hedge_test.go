@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestRaceWithResult(t *testing.T) {
+	t.Run("Returns the first success and cancels the others", func(t *testing.T) {
+		n := runtime.NumGoroutine()
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, 5*time.Second)
+
+		var (
+			calls     atomic.Int32
+			cancelled = make(chan struct{})
+		)
+		v, err := backoff.RaceWithResult(context.Background(), b, func(ctx context.Context) (int, error) {
+			call := calls.Add(1)
+			if call == 1 {
+				// Hang until the context gets cancelled.
+				<-ctx.Done()
+				close(cancelled)
+				return 0, ctx.Err()
+			}
+			return int(call), nil
+		})
+		if err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		if v != 2 {
+			t.Errorf("expected value to be \"%d\", but got \"%d\"", 2, v)
+		}
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Error("expected the first call to be cancelled")
+		}
+		waitForGoroutines(t, n)
+	})
+
+	t.Run("Is limited by MaxAttempts", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 0, 0, 0)
+
+		var calls atomic.Int32
+		_, err := backoff.RaceWithResult(context.Background(), b, func(context.Context) (int, error) {
+			calls.Add(1)
+			return 0, errRetry
+		})
+		if !errors.Is(err, errRetry) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errRetry, err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 3, calls.Load())
+		}
+	})
+
+	t.Run("Stops on a permanent error", func(t *testing.T) {
+		b := backoff.New(0, 2, 1*time.Hour, 1*time.Hour)
+
+		errPermanent := errors.New("permanent")
+		_, err := backoff.RaceWithResult(context.Background(), b, func(context.Context) (int, error) {
+			return 0, backoff.Permanent(errPermanent)
+		})
+		if err != errPermanent {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errPermanent, err)
+		}
+	})
+
+	t.Run("Returns the context error when cancelled", func(t *testing.T) {
+		b := backoff.New(0, 2, 1*time.Hour, 1*time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := backoff.RaceWithResult(ctx, b, func(context.Context) (int, error) {
+			cancel()
+			return 0, errRetry
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", context.Canceled, err)
+		}
+	})
+
+	t.Run("Returns the cause when cancelled", func(t *testing.T) {
+		b := backoff.New(0, 2, 1*time.Hour, 1*time.Hour)
+
+		errCause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		_, err := backoff.RaceWithResult(ctx, b, func(context.Context) (int, error) {
+			cancel(errCause)
+			return 0, errRetry
+		})
+		if !errors.Is(err, errCause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errCause, err)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"sync"
+)
+
+// nudger holds the state used by Nudge.
+type nudger struct {
+	mx sync.Mutex
+	// waiting is true while Next is waiting for the Timer.
+	waiting bool
+	// nudged receives a value when Nudge is called while waiting.
+	nudged chan struct{}
+}
+
+// Nudge ends the wait of the current call to Next early, which returns true
+// as if the delay had passed, so the next attempt runs immediately. This can
+// be used to retry as soon as something signals it may succeed, like a user
+// asking to refresh, without cancelling the context passed to Next. The
+// schedule is not changed, the following delays are the same as if the wait
+// was not ended early.
+//
+// Nudge may be called from any goroutine, like Pause. If Next is not waiting,
+// Nudge does nothing, so it never affects a later wait. If the Backoff is
+// paused, Next returns once it is resumed. Waits of Sleep cannot be nudged.
+func (b *Backoff) Nudge() {
+	n := b.nudger()
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	if !n.waiting {
+		return
+	}
+	select {
+	case n.nudged <- struct{}{}:
+	default:
+	}
+}
+
+// nudger returns the state used by Nudge, creating it if needed.
+func (b *Backoff) nudger() *nudger {
+	if n, ok := b.nudge.Load().(*nudger); ok {
+		return n
+	}
+	b.nudge.CompareAndSwap(nil, &nudger{nudged: make(chan struct{}, 1)})
+	return b.nudge.Load().(*nudger)
+}
+
+// begin marks a wait as in progress, returning the channel that receives a
+// value if Nudge is called before end.
+func (n *nudger) begin() <-chan struct{} {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	n.waiting = true
+	return n.nudged
+}
+
+// end marks the wait as done, discarding a value sent by Nudge that was not
+// received.
+func (n *nudger) end() {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	n.waiting = false
+	select {
+	case <-n.nudged:
+	default:
+	}
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestBackoff_Nudge(t *testing.T) {
+	t.Run("Ends a wait early", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Hour, time.Hour)
+		b.NextN(1)
+
+		done := make(chan bool)
+		go func() {
+			done <- b.Next(context.Background())
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		b.Nudge()
+		select {
+		case ok := <-done:
+			if !ok {
+				t.Error("expected Next to return true")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected Next to return once nudged")
+		}
+		if b.Attempt() != 2 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+		}
+		if _, waiting, _ := b.Status(); waiting {
+			t.Error("expected the wait to not be interrupted")
+		}
+	})
+
+	t.Run("Does nothing when not waiting", func(t *testing.T) {
+		b := backoff.New(0, 1, 20*time.Millisecond, 20*time.Millisecond)
+		b.Nudge()
+		b.NextN(1)
+		b.Nudge()
+
+		start := time.Now()
+		if !b.Next(context.Background()) {
+			t.Fatal("expected Next to return true")
+		}
+		if d := time.Since(start); d < 20*time.Millisecond {
+			t.Errorf("expected the full delay to be waited, but waited %s", d)
+		}
+	})
+
+	t.Run("Waits until resumed", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Hour, time.Hour)
+		b.NextN(1)
+
+		done := make(chan bool)
+		go func() {
+			done <- b.Next(context.Background())
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		b.Pause()
+		time.Sleep(10 * time.Millisecond)
+		b.Nudge()
+		select {
+		case <-done:
+			t.Fatal("expected Next to not return while paused")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		b.Resume()
+		select {
+		case ok := <-done:
+			if !ok {
+				t.Error("expected Next to return true")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected Next to return once resumed")
+		}
+	})
+}
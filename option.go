@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Option configures a Backoff created by NewWithOptions.
+type Option func(*Backoff)
+
+// NewWithOptions returns a new Backoff instance like New, with the given
+// options applied in order.
+func NewWithOptions(maxAttempts uint64, factor float64, min, max time.Duration, opts ...Option) *Backoff {
+	b := New(maxAttempts, factor, min, max)
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// WithLogger sets the Logger used by Retry. A nil logger disables logging.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Backoff) {
+		b.Logger = logger
+	}
+}
+
+// WithJitter sets the Jitter of the Backoff.
+func WithJitter(mode JitterMode) Option {
+	return func(b *Backoff) {
+		b.Jitter = mode
+	}
+}
+
+// WithStartAttempt sets the attempt the Backoff starts at, see
+// Backoff.SetAttempt.
+func WithStartAttempt(n uint64) Option {
+	return func(b *Backoff) {
+		b.SetAttempt(n)
+	}
+}
+
+// WithBudget sets the Budget shared by the Backoff, see Backoff.Budget.
+func WithBudget(budget *Budget) Option {
+	return func(b *Backoff) {
+		b.Budget = budget
+	}
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	b := backoff.NewWithOptions(_maxAttempts, _factor, _min, _max, backoff.WithLogger(logger))
+	if b.MaxAttempts != _maxAttempts || b.Factor != _factor || b.Min != _min || b.Max != _max {
+		t.Error("expected NewWithOptions to set the same fields as New")
+	}
+	if b.Timer == nil {
+		t.Error("expected Timer to not be nil")
+	}
+	if b.Logger != logger {
+		t.Error("expected WithLogger to set Logger")
+	}
+
+	// Ensure options are applied in order.
+	b = backoff.NewWithOptions(_maxAttempts, _factor, _min, _max, backoff.WithLogger(logger), backoff.WithLogger(nil))
+	if b.Logger != nil {
+		t.Error("expected the last option to take precedence")
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse returns a new Backoff from a compact policy string, which is useful for
+// accepting a Backoff as a single command-line flag.
+//
+// The policy string consists of a strategy followed by a colon and a comma
+// separated list of key=value pairs, for example:
+//
+//	exp:min=1s,max=30s,factor=2,attempts=5,jitter=full
+//
+// The only supported strategy is "exp". The supported keys are:
+//
+//   - min: Min, as a time.Duration string (default "0s")
+//   - max: Max, as a time.Duration string (default "0s")
+//   - factor: Factor, as a float (default 2)
+//   - attempts: MaxAttempts, as an unsigned integer (default 0, unlimited)
+//   - jitter: Jitter, either "none" (default), "full" or "equal"
+//
+// The returned Backoff is validated using Validate.
+func Parse(s string) (*Backoff, error) {
+	strategy, params, _ := strings.Cut(strings.TrimSpace(s), ":")
+	if strategy != "exp" {
+		return nil, fmt.Errorf("backoff: unknown strategy %q", strategy)
+	}
+
+	var fields []string
+	if params != "" {
+		fields = strings.Split(params, ",")
+	}
+
+	b := New(0, 2, 0, 0)
+	for _, param := range fields {
+		key, value, ok := strings.Cut(param, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("backoff: missing value for %q", key)
+		}
+
+		var err error
+		switch key {
+		case "min":
+			b.Min, err = time.ParseDuration(value)
+		case "max":
+			b.Max, err = time.ParseDuration(value)
+		case "factor":
+			b.Factor, err = strconv.ParseFloat(value, 64)
+		case "attempts":
+			b.MaxAttempts, err = strconv.ParseUint(value, 10, 64)
+		case "jitter":
+			b.Jitter, err = parseJitterMode(value)
+		default:
+			return nil, fmt.Errorf("backoff: unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("backoff: invalid value for %q: %w", key, err)
+		}
+	}
+
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestParse(t *testing.T) {
+	b, err := backoff.Parse("exp:min=1s,max=30s,factor=1.5,attempts=5,jitter=full")
+	if err != nil {
+		t.Fatalf("expected no error, but got \"%v\"", err)
+	}
+
+	for i, tc := range []struct {
+		field  string
+		expect any
+		value  any
+	}{
+		{
+			field:  "MaxAttempts",
+			expect: uint64(5),
+			value:  b.MaxAttempts,
+		},
+		{
+			field:  "Factor",
+			expect: 1.5,
+			value:  b.Factor,
+		},
+		{
+			field:  "Min",
+			expect: 1 * time.Second,
+			value:  b.Min,
+		},
+		{
+			field:  "Max",
+			expect: 30 * time.Second,
+			value:  b.Max,
+		},
+		{
+			field:  "Jitter",
+			expect: backoff.JitterFull,
+			value:  b.Jitter,
+		},
+	} {
+		if tc.expect != tc.value {
+			t.Errorf("Test #%d: expected %s to be \"%v\", but got \"%v\"", i+1, tc.field, tc.expect, tc.value)
+		}
+	}
+
+	t.Run("No parameters", func(t *testing.T) {
+		if _, err := backoff.Parse("exp"); err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		b, err := backoff.Parse("exp:max=1s")
+		if err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		if b.Factor != 2 || b.MaxAttempts != 0 || b.Min != 0 || b.Jitter != backoff.JitterNone {
+			t.Error("expected unset keys to use their default values")
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for i, s := range []string{
+			"",
+			"linear:min=1s",
+			"exp:min",
+			"exp:min=",
+			"exp:min=1",
+			"exp:max=forever",
+			"exp:factor=two",
+			"exp:attempts=-1",
+			"exp:jitter=some",
+			"exp:unknown=1",
+			"exp:min=2s,max=1s",
+		} {
+			if _, err := backoff.Parse(s); err == nil {
+				t.Errorf("Test #%d: expected an error when parsing \"%s\"", i+1, s)
+			}
+		}
+	})
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+	"sync"
+)
+
+// pauser holds the state used by Pause and Resume.
+type pauser struct {
+	mx sync.Mutex
+	// resumed is non-nil while the Backoff is paused, it is closed by Resume.
+	resumed chan struct{}
+	// pausing receives a value when Pause is called, to interrupt a wait.
+	pausing chan struct{}
+}
+
+// Pause suspends the backoff until Resume is called. If Next is waiting, the
+// Timer is stopped and the rest of the delay is waited once resumed. If Next
+// is not waiting, the next call to Next does not start waiting until resumed.
+// The time spent paused inside of Next does not count against MaxElapsed.
+//
+// Pause and Resume may be called from any goroutine, including while Next is
+// running, unlike every other method. Calling Pause while already paused does
+// nothing. The context passed to Next can still be cancelled while paused.
+// Waits of Sleep are not paused.
+func (b *Backoff) Pause() {
+	p := b.pauser()
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.resumed != nil {
+		return
+	}
+	p.resumed = make(chan struct{})
+	select {
+	case p.pausing <- struct{}{}:
+	default:
+	}
+}
+
+// Resume resumes the backoff after it was paused using Pause. Calling Resume
+// while not paused does nothing.
+func (b *Backoff) Resume() {
+	p, ok := b.pause.Load().(*pauser)
+	if !ok {
+		return
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.resumed != nil {
+		close(p.resumed)
+		p.resumed = nil
+	}
+}
+
+// Paused returns true if Pause was called without calling Resume afterwards.
+func (b *Backoff) Paused() bool {
+	return b.resumedChan() != nil
+}
+
+// pauser returns the state used by Pause and Resume, creating it if needed.
+func (b *Backoff) pauser() *pauser {
+	if p, ok := b.pause.Load().(*pauser); ok {
+		return p
+	}
+	b.pause.CompareAndSwap(nil, &pauser{pausing: make(chan struct{}, 1)})
+	return b.pause.Load().(*pauser)
+}
+
+// resumedChan returns the channel closed by Resume, or nil if the Backoff is
+// not paused.
+func (b *Backoff) resumedChan() chan struct{} {
+	p, ok := b.pause.Load().(*pauser)
+	if !ok {
+		return nil
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.resumed
+}
+
+// awaitResume blocks while the Backoff is paused, returning false if the
+// context was cancelled first. The time spent paused is excluded from the
+// time tracked for MaxElapsed.
+func (b *Backoff) awaitResume(ctx context.Context) bool {
+	resumed := b.resumedChan()
+	if resumed == nil {
+		return true
+	}
+
+	start := b.now()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-resumed:
+	}
+	if !b.start.IsZero() {
+		b.start = b.start.Add(b.now().Sub(start))
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestBackoff_Pause(t *testing.T) {
+	t.Run("Pauses a wait", func(t *testing.T) {
+		b := backoff.New(0, 1, 50*time.Millisecond, 50*time.Millisecond)
+		b.NextN(1)
+
+		done := make(chan bool)
+		go func() {
+			done <- b.Next(context.Background())
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		b.Pause()
+		b.Pause()
+		if !b.Paused() {
+			t.Error("expected the backoff to be paused")
+		}
+
+		select {
+		case <-done:
+			t.Fatal("expected Next to not return while paused")
+		case <-time.After(150 * time.Millisecond):
+		}
+
+		resumed := time.Now()
+		b.Resume()
+		b.Resume()
+		select {
+		case ok := <-done:
+			if !ok {
+				t.Error("expected Next to return true")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected Next to return once resumed")
+		}
+		if elapsed := time.Since(resumed); !backoff.DurationsEqual(elapsed, 40*time.Millisecond, 10*time.Millisecond) {
+			t.Errorf("expected only the rest of the delay to be waited, but waited %s", elapsed)
+		}
+		if b.Paused() {
+			t.Error("expected the backoff to not be paused")
+		}
+	})
+
+	t.Run("Excludes paused time from MaxElapsed", func(t *testing.T) {
+		b := backoff.New(0, 1, time.Millisecond, time.Millisecond)
+		b.MaxElapsed = 100 * time.Millisecond
+		b.Pause()
+
+		done := make(chan bool)
+		go func() {
+			done <- b.Next(context.Background())
+		}()
+
+		time.Sleep(200 * time.Millisecond)
+		b.Resume()
+		if !<-done {
+			t.Fatal("expected Next to return true")
+		}
+
+		if err := b.NextErr(context.Background()); err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+		if elapsed := b.Snapshot().Elapsed; elapsed >= 100*time.Millisecond {
+			t.Errorf("expected paused time to be excluded, but elapsed is %s", elapsed)
+		}
+	})
+
+	t.Run("Can be cancelled while paused", func(t *testing.T) {
+		b := backoff.New(0, 1, time.Hour, time.Hour)
+		b.NextN(1)
+		b.Pause()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan bool)
+		go func() {
+			done <- b.Next(ctx)
+		}()
+		cancel()
+
+		if <-done {
+			t.Error("expected Next to return false")
+		}
+	})
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+)
+
+// PingRetry calls ping until it returns nil, waiting for the backoff between
+// calls. It is intended for connection health checks, like waiting for a
+// database to become available on startup:
+//
+//	db, err := sql.Open("postgres", dsn)
+//	if err != nil {
+//		return err
+//	}
+//	if err := backoff.PingRetry(ctx, b, db.PingContext); err != nil {
+//		return err
+//	}
+//
+// PingRetry returns nil once ping succeeds, otherwise the error is returned
+// as described by Backoff.Retry.
+func PingRetry(ctx context.Context, b *Backoff, ping func(context.Context) error) error {
+	return b.Retry(ctx, func() error {
+		return ping(ctx)
+	})
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestPingRetry(t *testing.T) {
+	t.Run("Returns nil once ping succeeds", func(t *testing.T) {
+		b := newBackoffWithMockTimer(5, 2, 1*time.Second, 5*time.Second)
+
+		fn, calls := failN(2)
+		err := backoff.PingRetry(context.Background(), b, func(context.Context) error {
+			return fn()
+		})
+		if err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+		if *calls != 3 {
+			t.Errorf("expected ping to be called \"%d\" times, but got \"%d\"", 3, *calls)
+		}
+	})
+
+	t.Run("Returns the last error once the backoff gives up", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, 5*time.Second)
+
+		fn, _ := failN(5)
+		err := backoff.PingRetry(context.Background(), b, func(context.Context) error {
+			return fn()
+		})
+		if !errors.Is(err, errRetry) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errRetry, err)
+		}
+	})
+
+	t.Run("Passes the context to ping", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, 5*time.Second)
+
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, true)
+		err := backoff.PingRetry(ctx, b, func(ctx context.Context) error {
+			if v, _ := ctx.Value(key{}).(bool); !v {
+				return backoff.Permanent(errors.New("missing context value"))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+	})
+}
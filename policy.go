@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+// Policy is an immutable Backoff configuration, which can be shared between
+// goroutines to start new Backoffs with the same configuration.
+//
+// As every field of a Backoff is exported, sharing a Backoff as a template
+// allows any caller to modify it. A Policy holds its own copy of the
+// configuration that cannot be modified once created.
+//
+// The zero value starts Backoffs using Default.
+type Policy struct {
+	b *Backoff
+}
+
+// Policy returns a Policy with the configuration of the Backoff. Changes made
+// to the Backoff afterwards do not affect the Policy.
+func (b *Backoff) Policy() Policy {
+	return Policy{b: b.Clone()}
+}
+
+// Start returns a new Backoff using the configuration of the Policy, see
+// Backoff.Clone for details on how the Timer is handled.
+func (p Policy) Start() *Backoff {
+	if p.b == nil {
+		return Default.Clone()
+	}
+	return p.b.Clone()
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestPolicy(t *testing.T) {
+	b := backoff.New(3, 2, 1*time.Second, 5*time.Second)
+	b.NewTimer = func() backoff.Timer { return &mockTimer{} }
+	p := b.Policy()
+
+	// Changes to the Backoff must not affect the Policy.
+	b.MaxAttempts = 10
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			b := p.Start()
+			if b.MaxAttempts != 3 {
+				t.Errorf("expected max attempts to be \"%d\", but got \"%d\"", 3, b.MaxAttempts)
+			}
+			b.MaxAttempts = 1
+
+			var attempts uint64
+			for b.Next(context.Background()) {
+				attempts++
+			}
+			if attempts != 1 {
+				t.Errorf("expected number of attempts to be \"%d\", but got \"%d\"", 1, attempts)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if b := p.Start(); b.MaxAttempts != 3 || b.Attempt() != 0 {
+		t.Errorf("expected a fresh backoff with max attempts \"%d\", but got \"%d\" at attempt \"%d\"", 3, b.MaxAttempts, b.Attempt())
+	}
+
+	t.Run("Zero value uses Default", func(t *testing.T) {
+		var p backoff.Policy
+		if b := p.Start(); b == backoff.Default || b.MaxAttempts != backoff.Default.MaxAttempts {
+			t.Error("expected zero Policy to start a clone of Default")
+		}
+	})
+}
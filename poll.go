@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+)
+
+// Poll calls fn until it reports that it is done, waiting for the backoff
+// between calls. This is useful for operations that signal "not ready yet"
+// without an error, like waiting for a resource to become available.
+//
+// Poll returns nil once fn returns true, or the error returned by fn
+// immediately without retrying. If the backoff gives up, the error returned by
+// NextErr is returned, which is either ErrMaxAttempts, ErrMaxElapsed,
+// ErrMaxCappedWaits, ErrDeadline, ErrBudgetExhausted or the cause of the
+// context's cancellation.
+func Poll(ctx context.Context, b *Backoff, fn func() (done bool, err error)) error {
+	defer b.releaseTimer()
+	for {
+		if err := b.NextErr(ctx); err != nil {
+			return err
+		}
+
+		done, err := fn()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
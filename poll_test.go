@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matthewpi/backoff"
+)
+
+func TestPoll(t *testing.T) {
+	t.Run("Polls until done", func(t *testing.T) {
+		b := newBackoffWithMockTimer(5, 0, 0, 0)
+
+		var calls int
+		err := backoff.Poll(context.Background(), b, func() (bool, error) {
+			calls++
+			return calls == 3, nil
+		})
+		if err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+		if calls != 3 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+	})
+
+	t.Run("Returns an error immediately", func(t *testing.T) {
+		b := newBackoffWithMockTimer(5, 0, 0, 0)
+
+		var calls int
+		err := backoff.Poll(context.Background(), b, func() (bool, error) {
+			calls++
+			return false, errRetry
+		})
+		if err != errRetry {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errRetry, err)
+		}
+		if calls != 1 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 1, calls)
+		}
+	})
+
+	t.Run("Returns the reason the backoff gave up", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 0, 0, 0)
+
+		var calls int
+		err := backoff.Poll(context.Background(), b, func() (bool, error) {
+			calls++
+			return false, nil
+		})
+		if !errors.Is(err, backoff.ErrMaxAttempts) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", backoff.ErrMaxAttempts, err)
+		}
+		if calls != 3 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+	})
+
+	t.Run("Returns the context cause when cancelled", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+
+		cause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		err := backoff.Poll(ctx, b, func() (bool, error) {
+			cancel(cause)
+			return false, nil
+		})
+		if !errors.Is(err, cause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", cause, err)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JitterMode controls how randomness is applied to the delay between attempts.
+type JitterMode uint8
+
+const (
+	// JitterNone disables jitter, every delay is exactly the computed duration.
+	JitterNone JitterMode = iota
+	// JitterFull picks a random delay between zero and the computed duration.
+	JitterFull
+	// JitterEqual keeps half of the computed duration and randomizes the
+	// other half.
+	JitterEqual
+)
+
+// String returns the name of the JitterMode, "none", "full" or "equal".
+func (m JitterMode) String() string {
+	switch m {
+	case JitterNone:
+		return "none"
+	case JitterFull:
+		return "full"
+	case JitterEqual:
+		return "equal"
+	default:
+		return "JitterMode(" + strconv.Itoa(int(m)) + ")"
+	}
+}
+
+// MarshalJSON encodes the JitterMode as its name, see String.
+func (m JitterMode) MarshalJSON() ([]byte, error) {
+	if m > JitterEqual {
+		return nil, fmt.Errorf("backoff: unknown JitterMode %d", m)
+	}
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON decodes a JitterMode from its name, ignoring case.
+func (m *JitterMode) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("backoff: JitterMode must be a string: %w", err)
+	}
+	mode, err := parseJitterMode(s)
+	if err != nil {
+		return fmt.Errorf("backoff: %w", err)
+	}
+	*m = mode
+	return nil
+}
+
+// parseJitterMode returns the JitterMode with the given name, ignoring case.
+func parseJitterMode(s string) (JitterMode, error) {
+	switch strings.ToLower(s) {
+	case "none":
+		return JitterNone, nil
+	case "full":
+		return JitterFull, nil
+	case "equal":
+		return JitterEqual, nil
+	default:
+		return 0, fmt.Errorf("unknown jitter mode %q", s)
+	}
+}
+
+// Rand is used as an abstraction to swap out the source of randomness used
+// when applying jitter. Both *math/rand/v2.Rand and *math/rand.Rand satisfy
+// this interface.
+type Rand interface {
+	// Float64 returns a random number in the half-open interval [0.0, 1.0).
+	//
+	// Values outside of [0.0, 1.0] are treated as 1.0, which results in no
+	// jitter being applied to the delay.
+	Float64() float64
+}
+
+// globalRand implements the Rand interface using the top-level functions
+// provided by math/rand/v2. These use a per-thread source without locking and,
+// unlike math/rand, cannot be seeded globally, so they are safe to share
+// between any number of Backoffs without contention.
+type globalRand struct{}
+
+var _ Rand = globalRand{}
+
+func (globalRand) Float64() float64 {
+	return rand.Float64()
+}
+
+// cryptoRand implements the Rand interface by reading from crypto/rand.
+type cryptoRand struct{}
+
+var _ Rand = cryptoRand{}
+
+// NewCryptoRand returns a Rand that sources its values from crypto/rand.
+//
+// This should be used when the timing of retries must not be predictable. If
+// reading from crypto/rand fails, Float64 returns 1.0 which causes no jitter
+// to be applied rather than panicking.
+func NewCryptoRand() Rand {
+	return cryptoRand{}
+}
+
+func (cryptoRand) Float64() float64 {
+	var b [8]byte
+	// io.ReadFull is used instead of crypto/rand.Read as newer versions of Go
+	// crash the program if crypto/rand.Read fails.
+	if _, err := io.ReadFull(crand.Reader, b[:]); err != nil {
+		return 1
+	}
+	// Use the top 53 bits to fill the mantissa of a float64 in [0, 1).
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53)
+}
+
+// SeedRand replaces Rand with a new math/rand/v2 PCG source seeded with the
+// given seed, independent of the top-level functions. Combined with Reset,
+// the same seed always results in the same sequence of delays.
+//
+// This is intended for reproducible tests, it must not be used in production
+// as the delays become predictable. The source is not safe for concurrent
+// use, so it is shared by clones of the Backoff unless SeedRand is called on
+// the clone again.
+func (b *Backoff) SeedRand(seed int64) {
+	b.Rand = rand.New(rand.NewPCG(uint64(seed), 0))
+}
+
+// random returns the next random value from the Backoff's Rand, falling back
+// to math/rand/v2 if Rand is nil. Invalid values are normalized to 1.
+func (b *Backoff) random() float64 {
+	r := b.Rand
+	if r == nil {
+		r = globalRand{}
+	}
+	v := r.Float64()
+	if math.IsNaN(v) || v < 0 || v > 1 {
+		return 1
+	}
+	return v
+}
+
+// jitter applies the configured JitterMode and JitterFloor to the given
+// duration.
+func (b *Backoff) jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+
+	var j time.Duration
+	switch b.Jitter {
+	case JitterFull:
+		j = time.Duration(float64(d) * b.random())
+	case JitterEqual:
+		half := d / 2
+		j = half + time.Duration(float64(d-half)*b.random())
+	default:
+		return d
+	}
+
+	if floor := time.Duration(float64(d) * b.JitterFloor); j < floor {
+		return floor
+	}
+	return j
+}
+
+// jitterAbsolute adds a random amount of time up to JitterAbsolute to the
+// given duration.
+func (b *Backoff) jitterAbsolute(d time.Duration) time.Duration {
+	if b.JitterAbsolute <= 0 {
+		return d
+	}
+	j := time.Duration(float64(b.JitterAbsolute) * b.random())
+	if d > math.MaxInt64-j {
+		return math.MaxInt64
+	}
+	return d + j
+}
+
+// pickFactor picks a random factor for the current attempt when FactorJitter
+// is set.
+func (b *Backoff) pickFactor() {
+	if b.FactorJitter == 0 {
+		return
+	}
+
+	// Don't carry over factors from a previous sequence.
+	if b.n <= 1 || b.factors == 0 {
+		b.factors = 1
+	}
+	b.factors *= b.Factor - b.FactorJitter + 2*b.FactorJitter*b.random()
+}
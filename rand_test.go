@@ -0,0 +1,509 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+type fixedRand float64
+
+func (r fixedRand) Float64() float64 {
+	return float64(r)
+}
+
+// lockedRand implements backoff.Rand using a single source protected by a
+// mutex, like the top-level math/rand functions before Go 1.20.
+type lockedRand struct {
+	mx sync.Mutex
+	r  *rand.Rand
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.r.Float64()
+}
+
+// globalRandV1 implements backoff.Rand using the top-level math/rand
+// functions, which the default Rand used before switching to math/rand/v2.
+type globalRandV1 struct{}
+
+func (globalRandV1) Float64() float64 {
+	return rand.Float64()
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		mode     backoff.JitterMode
+		min, max time.Duration
+	}{
+		{
+			name: "None",
+			mode: backoff.JitterNone,
+			min:  2 * time.Second,
+			max:  2 * time.Second,
+		},
+		{
+			name: "Full",
+			mode: backoff.JitterFull,
+			min:  1 * time.Second, // Clamped to Min.
+			max:  2 * time.Second,
+		},
+		{
+			name: "Equal",
+			mode: backoff.JitterEqual,
+			min:  1 * time.Second,
+			max:  2 * time.Second,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				timer := &mockTimer{}
+				b := backoff.New(2, 2, 1*time.Second, 5*time.Second)
+				b.Timer = timer
+				b.Jitter = tc.mode
+				b.Rand = rand.New(rand.NewSource(int64(i)))
+
+				ctx := context.Background()
+				for b.Next(ctx) {
+				}
+
+				if len(timer.durations) != 1 {
+					t.Fatalf("expected timer to be started once, but got \"%d\"", len(timer.durations))
+				}
+				if d := timer.durations[0]; d < tc.min || d > tc.max {
+					t.Errorf("expected duration to be between \"%s\" and \"%s\", but got \"%s\"", tc.min, tc.max, d)
+					return
+				}
+			}
+		})
+	}
+
+	t.Run("Invalid Rand values disable jitter", func(t *testing.T) {
+		for _, v := range []float64{-1, 2} {
+			timer := &mockTimer{}
+			b := backoff.New(2, 2, 1*time.Second, 5*time.Second)
+			b.Timer = timer
+			b.Jitter = backoff.JitterFull
+			b.Rand = fixedRand(v)
+
+			ctx := context.Background()
+			for b.Next(ctx) {
+			}
+
+			if d := timer.durations[0]; d != 2*time.Second {
+				t.Errorf("expected duration to be \"%s\", but got \"%s\"", 2*time.Second, d)
+			}
+		}
+	})
+}
+
+func TestJitterMode_String(t *testing.T) {
+	for i, tc := range []struct {
+		mode   backoff.JitterMode
+		expect string
+	}{
+		{mode: backoff.JitterNone, expect: "none"},
+		{mode: backoff.JitterFull, expect: "full"},
+		{mode: backoff.JitterEqual, expect: "equal"},
+		{mode: 7, expect: "JitterMode(7)"},
+	} {
+		if s := tc.mode.String(); s != tc.expect {
+			t.Errorf("Test #%d: expected string to be \"%s\", but got \"%s\"", i+1, tc.expect, s)
+		}
+	}
+}
+
+func TestJitterMode_JSON(t *testing.T) {
+	t.Run("Round-trips", func(t *testing.T) {
+		for _, mode := range []backoff.JitterMode{backoff.JitterNone, backoff.JitterFull, backoff.JitterEqual} {
+			data, err := json.Marshal(mode)
+			if err != nil {
+				t.Errorf("expected no error, but got \"%v\"", err)
+				continue
+			}
+			if expect := `"` + mode.String() + `"`; string(data) != expect {
+				t.Errorf("expected JSON to be %s, but got %s", expect, data)
+			}
+
+			var decoded backoff.JitterMode
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Errorf("expected no error, but got \"%v\"", err)
+			}
+			if decoded != mode {
+				t.Errorf("expected mode to be \"%s\", but got \"%s\"", mode, decoded)
+			}
+		}
+	})
+
+	t.Run("Ignores case", func(t *testing.T) {
+		var mode backoff.JitterMode
+		if err := json.Unmarshal([]byte(`"Equal"`), &mode); err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+		if mode != backoff.JitterEqual {
+			t.Errorf("expected mode to be \"%s\", but got \"%s\"", backoff.JitterEqual, mode)
+		}
+	})
+
+	t.Run("Rejects unknown modes", func(t *testing.T) {
+		for _, data := range []string{`"some"`, `1`, `null`} {
+			var mode backoff.JitterMode
+			if err := json.Unmarshal([]byte(data), &mode); err == nil {
+				t.Errorf("expected an error for %s", data)
+			}
+		}
+		if _, err := json.Marshal(backoff.JitterMode(7)); err == nil {
+			t.Error("expected an error when marshalling an unknown mode")
+		}
+	})
+}
+
+func TestNewCryptoRand(t *testing.T) {
+	r := backoff.NewCryptoRand()
+	for i := 0; i < 100; i++ {
+		if v := r.Float64(); v < 0 || v >= 1 {
+			t.Errorf("expected value to be in [0, 1), but got \"%f\"", v)
+			return
+		}
+	}
+
+	t.Run("Falls back to no jitter on read errors", func(t *testing.T) {
+		reader := crand.Reader
+		crand.Reader = errReader{}
+		t.Cleanup(func() {
+			crand.Reader = reader
+		})
+
+		if v := r.Float64(); v != 1 {
+			t.Errorf("expected value to be \"1\", but got \"%f\"", v)
+		}
+	})
+}
+
+func TestBackoff_FactorJitter(t *testing.T) {
+	t.Run("Picks a factor for every attempt", func(t *testing.T) {
+		for _, tc := range []struct {
+			name   string
+			rand   float64
+			expect []time.Duration
+		}{
+			{
+				name:   "Lower bound",
+				rand:   0,
+				expect: []time.Duration{1500 * time.Millisecond, 2250 * time.Millisecond, 3375 * time.Millisecond},
+			},
+			{
+				name:   "Upper bound",
+				rand:   1,
+				expect: []time.Duration{2500 * time.Millisecond, 6250 * time.Millisecond, 15625 * time.Millisecond},
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				timer := &mockTimer{}
+				b := backoff.New(4, 2, 1*time.Second, time.Minute)
+				b.Timer = timer
+				b.FactorJitter = 0.5
+				b.Rand = fixedRand(tc.rand)
+
+				ctx := context.Background()
+				for b.Next(ctx) {
+				}
+
+				if len(timer.durations) != len(tc.expect) {
+					t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(tc.expect), len(timer.durations))
+				}
+				for i, d := range timer.durations {
+					if d != tc.expect[i] {
+						t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, tc.expect[i], d)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Is clamped", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 3*time.Second)
+		b.FactorJitter = 1.5
+		b.Rand = fixedRand(0)
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		if d := b.Duration(); d != b.Min {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Min, d)
+			return
+		}
+
+		b.Reset()
+		b.Rand = fixedRand(1)
+		b.Next(ctx)
+		b.Next(ctx)
+		if d := b.Duration(); d != b.Max {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Max, d)
+		}
+	})
+
+	t.Run("Reset does not carry over factors", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, time.Minute)
+		b.FactorJitter = 0.5
+		b.Rand = fixedRand(1)
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		b.Reset()
+
+		b.Rand = fixedRand(0)
+		b.Next(ctx)
+		b.Next(ctx)
+		if d, expect := b.Duration(), 2250*time.Millisecond; d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+	})
+}
+
+func TestBackoff_Duration_Jitter(t *testing.T) {
+	timer := &mockTimer{}
+	b := backoff.New(10, 2, 100*time.Millisecond, 5*time.Second)
+	b.Timer = timer
+	b.Jitter = backoff.JitterFull
+	b.Rand = rand.New(rand.NewSource(1))
+
+	// Before Next is called, Duration returns the delay without jitter.
+	if d := b.Duration(); d != 0 {
+		t.Errorf("expected duration to be \"%s\", but got \"%s\"", time.Duration(0), d)
+		return
+	}
+
+	var expect []time.Duration
+	ctx := context.Background()
+	for {
+		d := b.Duration()
+		// Calling Duration repeatedly must not pick a new delay.
+		if again := b.Duration(); again != d {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", d, again)
+			return
+		}
+		if !b.Next(ctx) {
+			break
+		}
+		if d != 0 {
+			expect = append(expect, d)
+		}
+	}
+
+	if len(timer.durations) != len(expect) {
+		t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+	}
+	for i, d := range timer.durations {
+		if d != expect[i] {
+			t.Errorf("Test #%d: expected waited duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+		}
+	}
+}
+
+func TestBackoff_JitterFloor(t *testing.T) {
+	t.Run("Holds across many draws", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(0, 2, 0, 10*time.Second)
+		b.Timer = timer
+		b.Strategy = constantStrategy(2 * time.Second)
+		b.Jitter = backoff.JitterFull
+		b.JitterFloor = 0.3
+		b.Rand = rand.New(rand.NewSource(1))
+
+		ctx := context.Background()
+		for i := 0; i < 1000; i++ {
+			b.Next(ctx)
+		}
+
+		floor := time.Duration(float64(2*time.Second) * b.JitterFloor)
+		for i, d := range timer.durations {
+			if d < floor || d > 2*time.Second {
+				t.Errorf("Test #%d: expected duration to be between \"%s\" and \"%s\", but got \"%s\"", i+1, floor, 2*time.Second, d)
+				return
+			}
+		}
+	})
+
+	t.Run("Min takes precedence", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(2, 2, 1500*time.Millisecond, 10*time.Second)
+		b.Timer = timer
+		b.Jitter = backoff.JitterFull
+		b.JitterFloor = 0.3
+		b.Rand = fixedRand(0)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		if d := timer.durations[0]; d != b.Min {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Min, d)
+		}
+	})
+
+	t.Run("Floor takes precedence", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.New(2, 2, 10*time.Millisecond, 10*time.Second)
+		b.Timer = timer
+		b.Jitter = backoff.JitterFull
+		b.JitterFloor = 0.9
+		b.Rand = fixedRand(0)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		if d, expect := timer.durations[0], 18*time.Millisecond; d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+	})
+}
+
+func TestBackoff_JitterAbsolute(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		rand   float64
+		max    time.Duration
+		expect time.Duration
+	}{
+		{
+			name:   "Lower bound",
+			rand:   0,
+			max:    time.Second,
+			expect: 20 * time.Millisecond,
+		},
+		{
+			name:   "Upper bound",
+			rand:   1,
+			max:    time.Second,
+			expect: 520 * time.Millisecond,
+		},
+		{
+			name:   "Clamped to Max",
+			rand:   1,
+			max:    100 * time.Millisecond,
+			expect: 100 * time.Millisecond,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			timer := &mockTimer{}
+			b := backoff.New(2, 2, 10*time.Millisecond, tc.max)
+			b.Timer = timer
+			b.JitterAbsolute = 500 * time.Millisecond
+			b.Rand = fixedRand(tc.rand)
+
+			ctx := context.Background()
+			for b.Next(ctx) {
+			}
+
+			if d := timer.durations[0]; d != tc.expect {
+				t.Errorf("expected duration to be \"%s\", but got \"%s\"", tc.expect, d)
+			}
+		})
+	}
+}
+
+func TestBackoff_SeedRand(t *testing.T) {
+	run := func(b *backoff.Backoff, seed int64) []time.Duration {
+		timer := &mockTimer{}
+		b.Timer = timer
+		b.Reset()
+		b.SeedRand(seed)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+		return timer.durations
+	}
+
+	b := backoff.New(10, 2, 100*time.Millisecond, 10*time.Second)
+	b.Jitter = backoff.JitterFull
+	b.FactorJitter = 0.5
+
+	first := run(b, 42)
+	again := run(b, 42)
+	other := run(b.Clone(), 42)
+	if len(first) != len(again) || len(first) != len(other) {
+		t.Fatalf("expected every sequence to have \"%d\" delays", len(first))
+	}
+	for i := range first {
+		if again[i] != first[i] || other[i] != first[i] {
+			t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\" and \"%s\"", i+1, first[i], again[i], other[i])
+		}
+	}
+
+	different := run(b, 7)
+	var same int
+	for i := range first {
+		if different[i] == first[i] {
+			same++
+		}
+	}
+	if same == len(first) {
+		t.Error("expected a different seed to result in different delays")
+	}
+}
+
+// BenchmarkBackoff_Jitter compares the default Rand shared by every goroutine,
+// backed by math/rand/v2, with the top-level math/rand functions, a single
+// locked source and a source per Backoff, run with -cpu to see the effect of
+// contention.
+func BenchmarkBackoff_Jitter(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		rand func() backoff.Rand
+	}{
+		{
+			name: "Default",
+			rand: func() backoff.Rand { return nil },
+		},
+		{
+			name: "MathRandV1",
+			rand: func() backoff.Rand { return globalRandV1{} },
+		},
+		{
+			name: "Locked",
+			rand: func() func() backoff.Rand {
+				r := &lockedRand{r: rand.New(rand.NewSource(1))}
+				return func() backoff.Rand { return r }
+			}(),
+		},
+		{
+			name: "Seeded",
+			rand: func() backoff.Rand { return randv2.New(randv2.NewPCG(1, 0)) },
+		},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				bo := backoff.New(0, 2, time.Millisecond, time.Second)
+				bo.Jitter = backoff.JitterFull
+				bo.Rand = bc.rand()
+				for pb.Next() {
+					bo.NextN(1)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// errReaderClosed is returned when reading from a closed retry reader.
+var errReaderClosed = errors.New("backoff: read from closed reader")
+
+// retryReader implements io.ReadCloser by re-opening the underlying reader
+// whenever a read fails.
+type retryReader struct {
+	ctx  context.Context
+	b    *Backoff
+	open func() (io.ReadCloser, error)
+
+	// r is the current underlying reader, nil if it needs to be opened.
+	r io.ReadCloser
+	// n is the number of bytes that have been returned by Read.
+	n int64
+	// last is the last error returned by open or the underlying reader.
+	last error
+	// err is returned by every call to Read once set.
+	err error
+}
+
+var _ io.ReadCloser = (*retryReader)(nil)
+
+// NewRetryReader returns an io.ReadCloser that reads from the reader returned
+// by open. If a read fails with an error other than io.EOF, the reader is
+// closed and re-opened using open after waiting for the backoff.
+//
+// When re-opening, the bytes that were already read are skipped, either by
+// seeking if the new reader implements io.Seeker, or by discarding them. This
+// requires open to return the same content every time it is called, if it
+// does not, the content returned from the reader will be corrupted.
+//
+// The backoff is reset when a read succeeds, so its limits only apply to
+// consecutive failures. Once the backoff gives up, the last error from open
+// or from reading is returned, or the cause of the context's cancellation.
+func NewRetryReader(ctx context.Context, b *Backoff, open func() (io.ReadCloser, error)) io.ReadCloser {
+	return &retryReader{
+		ctx:  ctx,
+		b:    b,
+		open: open,
+	}
+}
+
+func (r *retryReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if err := context.Cause(r.ctx); err != nil {
+			r.err = err
+			break
+		}
+
+		if r.r == nil {
+			if err := r.connect(); err != nil {
+				r.err = err
+				break
+			}
+		}
+
+		n, err := r.r.Read(p)
+		r.n += int64(n)
+		if err == nil || errors.Is(err, io.EOF) {
+			if n > 0 {
+				r.b.Reset()
+			}
+			return n, err
+		}
+
+		// Read failed, re-open the reader on the next iteration.
+		r.last = err
+		_ = r.r.Close()
+		r.r = nil
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return 0, r.err
+}
+
+// connect opens a new reader and skips any bytes that were already read.
+func (r *retryReader) connect() error {
+	for r.b.Next(r.ctx) {
+		rc, err := r.open()
+		if err != nil {
+			r.last = err
+			continue
+		}
+		if err := r.skip(rc); err != nil {
+			_ = rc.Close()
+			r.last = err
+			continue
+		}
+		r.r = rc
+		return nil
+	}
+
+	if err := context.Cause(r.ctx); err != nil {
+		return err
+	}
+	if r.last == nil {
+		return io.ErrUnexpectedEOF
+	}
+	return r.last
+}
+
+// skip advances the given reader past the bytes that were already read.
+func (r *retryReader) skip(rc io.ReadCloser) error {
+	if r.n == 0 {
+		return nil
+	}
+
+	if s, ok := rc.(io.Seeker); ok {
+		_, err := s.Seek(r.n, io.SeekStart)
+		return err
+	}
+
+	if _, err := io.CopyN(io.Discard, rc, r.n); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *retryReader) Close() error {
+	if r.err == nil {
+		r.err = errReaderClosed
+	}
+	if r.r == nil {
+		return nil
+	}
+	err := r.r.Close()
+	r.r = nil
+	return err
+}
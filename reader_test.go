@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/matthewpi/backoff"
+)
+
+var errFlaky = errors.New("flaky")
+
+// flakyReader returns errFlaky once limit bytes have been read.
+type flakyReader struct {
+	r     io.Reader
+	limit int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if r.limit <= 0 {
+		return 0, errFlaky
+	}
+	if len(p) > r.limit {
+		p = p[:r.limit]
+	}
+	n, err := r.r.Read(p)
+	r.limit -= n
+	return n, err
+}
+
+// flakyOpener returns an open function for NewRetryReader where the first
+// len(limits) readers fail after reading the corresponding number of bytes.
+func flakyOpener(data string, seek bool, limits ...int) (open func() (io.ReadCloser, error), opened *int) {
+	opened = new(int)
+	open = func() (io.ReadCloser, error) {
+		*opened++
+
+		sr := strings.NewReader(data)
+		var r io.Reader = sr
+		if !seek {
+			// Hide the io.Seeker implementation of strings.Reader.
+			r = struct{ io.Reader }{sr}
+		}
+		if *opened <= len(limits) {
+			r = &flakyReader{r: r, limit: limits[*opened-1]}
+		}
+		if s, ok := r.(io.Seeker); ok && seek {
+			return struct {
+				io.Reader
+				io.Seeker
+				io.Closer
+			}{r, s, io.NopCloser(nil)}, nil
+		}
+		return io.NopCloser(r), nil
+	}
+	return open, opened
+}
+
+func TestNewRetryReader(t *testing.T) {
+	const data = "hello, world! this is a flaky stream."
+
+	for _, seek := range []bool{false, true} {
+		name := "Discard"
+		if seek {
+			name = "Seek"
+		}
+		t.Run(name, func(t *testing.T) {
+			open, opened := flakyOpener(data, seek, 5, 12, 0)
+
+			r := backoff.NewRetryReader(context.Background(), newBackoffWithMockTimer(0, 0, 0, 0), open)
+			defer r.Close()
+
+			b, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("expected no error, but got \"%v\"", err)
+			}
+			if string(b) != data {
+				t.Errorf("expected data to be \"%s\", but got \"%s\"", data, string(b))
+			}
+			if *opened != 4 {
+				t.Errorf("expected reader to be opened \"%d\" times, but got \"%d\"", 4, *opened)
+			}
+		})
+	}
+
+	t.Run("Returns the last error once the backoff gives up", func(t *testing.T) {
+		open, _ := flakyOpener(data, false, 5, 0, 0)
+
+		r := backoff.NewRetryReader(context.Background(), newBackoffWithMockTimer(2, 0, 0, 0), open)
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		if !errors.Is(err, errFlaky) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errFlaky, err)
+		}
+		if string(b) != data[:5] {
+			t.Errorf("expected data to be \"%s\", but got \"%s\"", data[:5], string(b))
+		}
+	})
+
+	t.Run("Returns open errors", func(t *testing.T) {
+		errOpen := errors.New("open failed")
+		open := func() (io.ReadCloser, error) {
+			return nil, errOpen
+		}
+
+		r := backoff.NewRetryReader(context.Background(), newBackoffWithMockTimer(3, 0, 0, 0), open)
+		defer r.Close()
+
+		if _, err := r.Read(make([]byte, 8)); !errors.Is(err, errOpen) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errOpen, err)
+		}
+	})
+
+	t.Run("Returns the context cause when cancelled", func(t *testing.T) {
+		open, opened := flakyOpener(data, false)
+
+		cause := errors.New("cancelled")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(cause)
+
+		r := backoff.NewRetryReader(ctx, newBackoffWithMockTimer(0, 0, 0, 0), open)
+		defer r.Close()
+
+		if _, err := r.Read(make([]byte, 8)); !errors.Is(err, cause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", cause, err)
+		}
+		if *opened != 0 {
+			t.Errorf("expected reader to not be opened, but it was opened \"%d\" times", *opened)
+		}
+	})
+
+	t.Run("Read fails after Close", func(t *testing.T) {
+		open, _ := flakyOpener(data, false)
+
+		r := backoff.NewRetryReader(context.Background(), newBackoffWithMockTimer(0, 0, 0, 0), open)
+		if err := r.Close(); err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		if _, err := r.Read(make([]byte, 8)); err == nil {
+			t.Error("expected an error when reading from a closed reader")
+		}
+	})
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Reconnect repeatedly calls connect, waiting for the backoff between calls.
+// connect should block for as long as the connection is healthy, returning an
+// error once it drops.
+//
+// If connect ran for at least b.ResetAfter before returning, the backoff is
+// reset so a connection that was healthy for a while reconnects quickly. If
+// ResetAfter is zero, the backoff is never reset.
+//
+// Reconnect returns nil if connect returns nil, the error wrapped by a
+// PermanentError if connect returns one, or the cause of the context's
+// cancellation. If the backoff gives up, the last error returned by connect
+// is returned.
+func Reconnect(ctx context.Context, b *Backoff, connect func(context.Context) error) error {
+	var last error
+	for b.Next(ctx) {
+		start := b.now()
+		err := connect(ctx)
+		if err == nil {
+			return nil
+		}
+		if perr, ok := asPermanent(err); ok {
+			return perr.Err
+		}
+		if cerr := context.Cause(ctx); cerr != nil {
+			return cerr
+		}
+
+		if b.ResetAfter > 0 && b.now().Sub(start) >= b.ResetAfter {
+			b.Reset()
+		}
+		last = err
+	}
+
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+	return last
+}
+
+// ReconnectPolicy describes how a long-lived client reconnects. Failures are
+// retried in bursts, every burst is limited by the MaxAttempts of Policy, but
+// once a connection stays healthy for HealthyAfter, the next failure starts a
+// new burst with the attempt and delays reset, as if the Backoff had just been
+// started.
+//
+// The zero value reconnects using Default and never starts a new burst.
+type ReconnectPolicy struct {
+	// Policy is used to start the Backoff for every call to Run, its
+	// MaxAttempts limits the number of attempts in a single burst.
+	Policy Policy
+	// HealthyAfter sets the ResetAfter of the Backoff started by Policy, see
+	// Backoff.Succeeded. If set to 0, the ResetAfter of the Backoff is kept,
+	// if that is 0 as well, the Backoff is never reset.
+	HealthyAfter time.Duration
+}
+
+// Run repeatedly calls connect, waiting for the backoff between calls, until
+// a burst gives up. connect should call connected once the connection has been
+// established, then block for as long as it is healthy, returning an error
+// once it drops. connected must not be called after connect returns, calling
+// it more than once has no effect.
+//
+// connected calls Succeeded on the Backoff, so if the connection was healthy
+// for at least HealthyAfter since connected was called, the Backoff is reset,
+// so the next failure reconnects immediately and gets a full burst of
+// attempts. The time spent waiting before connecting does not count.
+//
+// Run returns nil if connect returns nil, the error wrapped by a
+// PermanentError if connect returns one, or the cause of the context's
+// cancellation. If a burst gives up, the last error returned by connect is
+// returned.
+func (p ReconnectPolicy) Run(ctx context.Context, connect func(ctx context.Context, connected func()) error) error {
+	b := p.Policy.Start()
+	if p.HealthyAfter > 0 {
+		b.ResetAfter = p.HealthyAfter
+	}
+
+	var last error
+	for b.Next(ctx) {
+		var once sync.Once
+		err := connect(ctx, func() {
+			if b.ResetAfter > 0 {
+				once.Do(b.Succeeded)
+			}
+		})
+		if err == nil {
+			return nil
+		}
+		if perr, ok := asPermanent(err); ok {
+			return perr.Err
+		}
+		if cerr := context.Cause(ctx); cerr != nil {
+			return cerr
+		}
+		last = err
+	}
+
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+	return last
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+var errDropped = errors.New("connection dropped")
+
+func TestReconnect(t *testing.T) {
+	t.Run("Returns the last error once the backoff gives up", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 0, 0, 0)
+
+		var calls int
+		err := backoff.Reconnect(context.Background(), b, func(context.Context) error {
+			calls++
+			return errDropped
+		})
+		if !errors.Is(err, errDropped) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errDropped, err)
+		}
+		if calls != 3 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+	})
+
+	t.Run("Stops on a permanent error", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+
+		errAuth := errors.New("unauthorized")
+		var calls int
+		err := backoff.Reconnect(context.Background(), b, func(context.Context) error {
+			calls++
+			if calls == 2 {
+				return backoff.Permanent(errAuth)
+			}
+			return errDropped
+		})
+		if err != errAuth {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errAuth, err)
+		}
+		if calls != 2 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 2, calls)
+		}
+	})
+
+	t.Run("Returns nil when connect returns nil", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+
+		err := backoff.Reconnect(context.Background(), b, func(context.Context) error {
+			return nil
+		})
+		if err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+	})
+
+	t.Run("Returns the context cause when cancelled", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+
+		cause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		err := backoff.Reconnect(ctx, b, func(context.Context) error {
+			cancel(cause)
+			return errDropped
+		})
+		if !errors.Is(err, cause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", cause, err)
+		}
+	})
+
+	t.Run("Resets after a healthy connection", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 0, 0, 0)
+		b.ResetAfter = 10 * time.Millisecond
+
+		var calls int
+		err := backoff.Reconnect(context.Background(), b, func(context.Context) error {
+			calls++
+			if calls == 2 {
+				// Simulate a connection that stayed up for a while.
+				time.Sleep(20 * time.Millisecond)
+			}
+			return errDropped
+		})
+		if !errors.Is(err, errDropped) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errDropped, err)
+		}
+		// Two calls before the reset, then three more after.
+		if calls != 5 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 5, calls)
+		}
+	})
+}
+
+func TestReconnectPolicy(t *testing.T) {
+	// runWith runs a ReconnectPolicy using the given HealthyAfter and
+	// ResetAfter, where connect stays connected for the given durations in
+	// order, a negative duration fails before connecting.
+	runWith := func(healthyAfter, resetAfter time.Duration, healthy ...time.Duration) (calls int, durations []time.Duration, err error) {
+		clock := newMockClock()
+		timer := &mockTimer{}
+		b := backoff.New(3, 2, 1*time.Second, time.Minute)
+		b.Timer = timer
+		b.Clock = clock
+		b.ResetAfter = resetAfter
+
+		p := backoff.ReconnectPolicy{
+			Policy:       b.Policy(),
+			HealthyAfter: healthyAfter,
+		}
+		err = p.Run(context.Background(), func(_ context.Context, connected func()) error {
+			calls++
+			if calls <= len(healthy) && healthy[calls-1] >= 0 {
+				connected()
+				clock.Advance(healthy[calls-1])
+			}
+			return errDropped
+		})
+		return calls, timer.durations, err
+	}
+	// run runs a ReconnectPolicy with a HealthyAfter of 30s.
+	run := func(healthy ...time.Duration) (calls int, durations []time.Duration, err error) {
+		return runWith(30*time.Second, 0, healthy...)
+	}
+
+	t.Run("Gives up after a burst", func(t *testing.T) {
+		calls, durations, err := run()
+		if !errors.Is(err, errDropped) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errDropped, err)
+		}
+		if calls != 3 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+		if len(durations) != 2 || durations[0] != 2*time.Second || durations[1] != 4*time.Second {
+			t.Errorf("expected the timer to be started with [2s 4s], but got %v", durations)
+		}
+	})
+
+	t.Run("Starts a new burst after recovering", func(t *testing.T) {
+		calls, durations, err := run(-1, -1, time.Minute)
+		if !errors.Is(err, errDropped) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errDropped, err)
+		}
+		// Three calls in the first burst, then a full second burst.
+		if calls != 6 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 6, calls)
+		}
+		// The second burst reconnects immediately and starts from Min again.
+		expect := []time.Duration{2 * time.Second, 4 * time.Second, 2 * time.Second, 4 * time.Second}
+		if len(durations) != len(expect) {
+			t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(durations))
+		}
+		for i, d := range durations {
+			if d != expect[i] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+			}
+		}
+	})
+
+	t.Run("Uses ResetAfter without HealthyAfter", func(t *testing.T) {
+		calls, _, _ := runWith(0, 30*time.Second, -1, -1, time.Minute)
+		if calls != 6 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 6, calls)
+		}
+		calls, _, _ = runWith(0, 0, -1, -1, time.Minute)
+		if calls != 3 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+	})
+
+	t.Run("Does not start a new burst after a short connection", func(t *testing.T) {
+		calls, _, err := run(-1, 10*time.Second)
+		if !errors.Is(err, errDropped) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errDropped, err)
+		}
+		if calls != 3 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+	})
+	t.Run("Does not count the wait as healthy", func(t *testing.T) {
+		// Every delay is longer than HealthyAfter, and the timer advances the
+		// clock by it, but every connection drops after a second.
+		clock := newMockClock()
+		b := backoff.New(3, 2, 20*time.Second, time.Minute)
+		b.Timer = newClockTimer(clock)
+		b.Clock = clock
+
+		p := backoff.ReconnectPolicy{
+			Policy:       b.Policy(),
+			HealthyAfter: 30 * time.Second,
+		}
+		var calls int
+		err := p.Run(context.Background(), func(_ context.Context, connected func()) error {
+			calls++
+			if calls > 50 {
+				return backoff.Permanent(errors.New("burst never gave up"))
+			}
+			connected()
+			clock.Advance(time.Second)
+			return errDropped
+		})
+		if !errors.Is(err, errDropped) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errDropped, err)
+		}
+		if calls != 3 {
+			t.Errorf("expected connect to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Default is the Backoff used by the package-level Retry function. It is
+// cloned for every call to Retry, so its state is never shared.
+//
+// Default is meant to be set once during program initialization, it must not
+// be modified while Retry may be called.
+var Default = New(10, 2, 100*time.Millisecond, 10*time.Second)
+
+// Retry calls fn using a clone of Default, see Backoff.Retry for details.
+func Retry(ctx context.Context, fn func() error) error {
+	return Default.Clone().Retry(ctx, fn)
+}
+
+// Retry calls fn until it returns nil, waiting for the backoff between calls.
+//
+// If fn returns a PermanentError, the error wrapped by it is returned without
+// retrying. If the backoff gives up because a limit was reached, a
+// *GaveUpError is returned with the number of attempts made and the time
+// elapsed. It wraps the last error returned by fn and the sentinel describing
+// the limit, either ErrMaxAttempts, ErrMaxElapsed, ErrMaxCappedWaits,
+// ErrDeadline or ErrBudgetExhausted, so both can be matched using errors.Is.
+// The message of the returned error is the sentinel's message followed by the
+// last error's. If the context is cancelled, the cause of the cancellation,
+// see context.Cause, is returned joined with the last error returned by fn.
+//
+// If Logger is set, every retry is logged at debug level and a warning is
+// logged if a limit is reached. The OnAttempt, OnWait and OnGiveUp hooks are
+// called if set. If SubtractWork is set, the time taken by fn is subtracted
+// from the delay before the following attempt.
+func (b *Backoff) Retry(ctx context.Context, fn func() error) error {
+	defer b.releaseTimer()
+	err := b.retry(ctx, fn)
+	if err != nil && b.OnGiveUp != nil {
+		b.OnGiveUp(b.n, err)
+	}
+	return err
+}
+
+// RetryFunc returns a function that calls fn using Retry with a clone of b,
+// see Backoff.Clone, which makes it suitable for errgroup.Group.Go without
+// sharing the state of b between goroutines:
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	for _, url := range urls {
+//		g.Go(backoff.RetryFunc(ctx, b, func() error {
+//			return fetch(ctx, url)
+//		}))
+//	}
+//	err := g.Wait()
+//
+// b is cloned when RetryFunc is called, so the returned function may be
+// called from any goroutine, but only once at a time.
+func RetryFunc(ctx context.Context, b *Backoff, fn func() error) func() error {
+	c := b.Clone()
+	return func() error {
+		return c.Retry(ctx, fn)
+	}
+}
+
+// MustRetry calls fn like Retry, but panics with the error returned by Retry
+// if fn did not succeed. It is intended for initialization code where the
+// program cannot function if fn fails, like connecting to a required
+// dependency at startup.
+func (b *Backoff) MustRetry(ctx context.Context, fn func() error) {
+	if err := b.Retry(ctx, fn); err != nil {
+		panic(err)
+	}
+}
+
+// RetryCtx behaves like Retry, but passes a context to fn that carries the
+// current attempt, starting at 1, which can be retrieved using
+// AttemptFromContext. If AttemptTimeout is set, the context is cancelled once
+// it has passed.
+func (b *Backoff) RetryCtx(ctx context.Context, fn func(context.Context) error) error {
+	return b.Retry(ctx, func() error {
+		ctx := context.WithValue(ctx, attemptKey{}, b.n)
+		if b.AttemptTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, b.AttemptTimeout)
+			defer cancel()
+		}
+		return fn(ctx)
+	})
+}
+
+// RetryResult calls fn using RetryCtx until it succeeds, returning the value
+// returned by the call that succeeded. Every error is retried unless it is a
+// PermanentError or retryable returns false for it, in which case it is
+// returned immediately. If retryable is nil, every error is retried.
+//
+// If the backoff gives up, the zero value of T is returned along with the
+// error returned by Retry.
+func RetryResult[T any](ctx context.Context, b *Backoff, fn func(context.Context) (T, error), retryable func(error) bool) (T, error) {
+	var v T
+	err := b.RetryCtx(ctx, func(ctx context.Context) error {
+		r, err := fn(ctx)
+		if err != nil {
+			if retryable != nil && !retryable(err) {
+				return Permanent(err)
+			}
+			return err
+		}
+		v = r
+		return nil
+	})
+	return v, err
+}
+
+// attemptKey is the context key used to store the current attempt.
+type attemptKey struct{}
+
+// AttemptFromContext returns the attempt stored in a context passed to fn by
+// RetryCtx. The returned bool is false if the context does not carry an
+// attempt.
+func AttemptFromContext(ctx context.Context) (uint64, bool) {
+	attempt, ok := ctx.Value(attemptKey{}).(uint64)
+	return attempt, ok
+}
+
+// retry implements Retry.
+func (b *Backoff) retry(ctx context.Context, fn func() error) error {
+	var err error
+	for {
+		if stop := b.NextErr(ctx); stop != nil {
+			if ctx.Err() != nil || stop == context.DeadlineExceeded {
+				return errors.Join(waitErr(ctx), err)
+			}
+			b.log(ctx, slog.LevelWarn, "giving up", err)
+			return b.giveUp(stop, err)
+		}
+
+		if b.OnAttempt != nil {
+			b.OnAttempt(b.n)
+		}
+		started := b.now()
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if b.SubtractWork && b.hasNext {
+			b.next = max(b.next-b.now().Sub(started), 0)
+		}
+		if perr, ok := asPermanent(err); ok {
+			return perr.Err
+		}
+
+		if b.exhausted() {
+			b.log(ctx, slog.LevelWarn, "giving up", err)
+			return b.giveUp(ErrMaxAttempts, err)
+		}
+		b.log(ctx, slog.LevelDebug, "retrying", err)
+		if b.OnWait != nil {
+			b.OnWait(b.Duration())
+		}
+	}
+}
+
+// log logs a message with the current attempt, the delay before the next
+// attempt and the given error using Logger, if set.
+func (b *Backoff) log(ctx context.Context, level slog.Level, msg string, err error) {
+	if b.Logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{slog.Uint64("attempt", b.n)}
+	if !b.exhausted() {
+		attrs = append(attrs, slog.Duration("delay", b.Duration()))
+	}
+	attrs = append(attrs, slog.Any("error", err))
+	b.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,533 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+var errRetry = errors.New("try again")
+
+// failN returns a function that fails n times before succeeding.
+func failN(n int) (fn func() error, calls *int) {
+	calls = new(int)
+	fn = func() error {
+		*calls++
+		if *calls <= n {
+			return errRetry
+		}
+		return nil
+	}
+	return fn, calls
+}
+
+func TestBackoff_Retry(t *testing.T) {
+	t.Run("Retries until fn succeeds", func(t *testing.T) {
+		b := newBackoffWithMockTimer(5, 0, 0, 0)
+
+		fn, calls := failN(3)
+		if err := b.Retry(context.Background(), fn); err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+		if *calls != 4 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 4, *calls)
+		}
+	})
+
+	t.Run("Returns the last error once the backoff gives up", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 0, 0, 0)
+
+		fn, calls := failN(5)
+		if err := b.Retry(context.Background(), fn); !errors.Is(err, errRetry) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errRetry, err)
+		}
+		if *calls != 3 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 3, *calls)
+		}
+	})
+
+	t.Run("Wraps the last error with the give up reason", func(t *testing.T) {
+		for i, tc := range []struct {
+			modify func(b *backoff.Backoff)
+			expect error
+		}{
+			{
+				modify: func(b *backoff.Backoff) { b.MaxAttempts = 3 },
+				expect: backoff.ErrMaxAttempts,
+			},
+			{
+				modify: func(b *backoff.Backoff) { b.MaxCappedWaits = 1 },
+				expect: backoff.ErrMaxCappedWaits,
+			},
+		} {
+			b := newBackoffWithMockTimer(0, 2, 1*time.Second, 2*time.Second)
+			tc.modify(b)
+
+			fn, _ := failN(10)
+			err := b.Retry(context.Background(), fn)
+			if !errors.Is(err, tc.expect) {
+				t.Errorf("Test #%d: expected error to be \"%v\", but got \"%v\"", i+1, tc.expect, err)
+			}
+			if !errors.Is(err, errRetry) {
+				t.Errorf("Test #%d: expected error to be \"%v\", but got \"%v\"", i+1, errRetry, err)
+			}
+			if expect := tc.expect.Error() + ": " + errRetry.Error(); err.Error() != expect {
+				t.Errorf("Test #%d: expected error message to be \"%s\", but got \"%s\"", i+1, expect, err.Error())
+			}
+		}
+	})
+
+	t.Run("Returns a GaveUpError", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, 2*time.Second)
+
+		fn, _ := failN(10)
+		err := b.Retry(context.Background(), fn)
+		var gerr *backoff.GaveUpError
+		if !errors.As(err, &gerr) {
+			t.Fatalf("expected error to be a GaveUpError, but got \"%v\"", err)
+		}
+		if gerr.Attempts != 3 {
+			t.Errorf("expected attempts to be \"%d\", but got \"%d\"", 3, gerr.Attempts)
+		}
+		if gerr.Elapsed < 0 {
+			t.Errorf("expected elapsed to not be negative, but got \"%s\"", gerr.Elapsed)
+		}
+		if gerr.Reason != backoff.ErrMaxAttempts || gerr.Last != errRetry {
+			t.Errorf("expected reason and last error to be \"%v\" and \"%v\", but got \"%v\" and \"%v\"", backoff.ErrMaxAttempts, errRetry, gerr.Reason, gerr.Last)
+		}
+
+		_, err = backoff.RetryResult(context.Background(), b.Clone(), func(context.Context) (int, error) {
+			return 0, errRetry
+		}, nil)
+		if !errors.As(err, &gerr) || gerr.Attempts != 3 {
+			t.Errorf("expected RetryResult to return a GaveUpError after \"%d\" attempts, but got \"%v\"", 3, err)
+		}
+	})
+
+	t.Run("Stops on a permanent error", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+
+		errPermanent := errors.New("permanent")
+		var calls int
+		err := b.Retry(context.Background(), func() error {
+			calls++
+			return backoff.Permanent(errPermanent)
+		})
+		if err != errPermanent {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errPermanent, err)
+		}
+		if calls != 1 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 1, calls)
+		}
+	})
+
+	t.Run("Returns the context error when cancelled", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		err := b.Retry(ctx, func() error {
+			cancel()
+			return errRetry
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", context.Canceled, err)
+		}
+		if !errors.Is(err, errRetry) {
+			t.Errorf("expected error to wrap \"%v\", but got \"%v\"", errRetry, err)
+		}
+	})
+
+	t.Run("Returns the context cause when cancelled", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+
+		cause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		err := b.Retry(ctx, func() error {
+			cancel(cause)
+			return errRetry
+		})
+		if !errors.Is(err, cause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", cause, err)
+		}
+		if errors.Is(err, context.Canceled) {
+			t.Errorf("expected error not to be \"%v\"", context.Canceled)
+		}
+		if !errors.Is(err, errRetry) {
+			t.Errorf("expected error to wrap \"%v\", but got \"%v\"", errRetry, err)
+		}
+	})
+}
+
+func TestBackoff_Retry_TimeoutCause(t *testing.T) {
+	cause := errors.New("request budget spent")
+	for i := 0; i < 5; i++ {
+		b := backoff.New(0, 2, time.Second, time.Second)
+
+		ctx, cancel := context.WithTimeoutCause(context.Background(), 10*time.Millisecond, cause)
+		err := b.Retry(ctx, func() error { return errRetry })
+		cancel()
+		if !errors.Is(err, cause) || !errors.Is(err, errRetry) {
+			t.Errorf("Test #%d: expected error to wrap \"%v\" and \"%v\", but got \"%v\"", i+1, cause, errRetry, err)
+		}
+		var gerr *backoff.GaveUpError
+		if errors.As(err, &gerr) {
+			t.Errorf("Test #%d: expected error to not be a GaveUpError", i+1)
+		}
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		b := backoff.New(0, 2, time.Second, time.Second)
+		b.DryRun = true
+
+		ctx, cancel := context.WithTimeoutCause(context.Background(), time.Minute, cause)
+		defer cancel()
+		b.SetFirstDelay(time.Hour)
+		err := b.Retry(ctx, func() error { return errRetry })
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", context.DeadlineExceeded, err)
+		}
+		var gerr *backoff.GaveUpError
+		if errors.As(err, &gerr) {
+			t.Error("expected error to not be a GaveUpError")
+		}
+	})
+}
+
+func TestBackoff_Retry_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	b := newBackoffWithMockTimer(3, 2, 1*time.Second, 5*time.Second)
+	b.Logger = logger
+
+	fn, _ := failN(5)
+	if err := b.Retry(context.Background(), fn); !errors.Is(err, errRetry) {
+		t.Errorf("expected error to be \"%v\", but got \"%v\"", errRetry, err)
+	}
+
+	expect := []string{
+		`level=DEBUG msg=retrying attempt=1 delay=2s error="try again"`,
+		`level=DEBUG msg=retrying attempt=2 delay=4s error="try again"`,
+		`level=WARN msg="giving up" attempt=3 error="try again"`,
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(expect) {
+		t.Fatalf("expected \"%d\" log lines, but got \"%d\":\n%s", len(expect), len(lines), buf.String())
+	}
+	for i, line := range lines {
+		if line != expect[i] {
+			t.Errorf("Test #%d: expected log line to be \"%s\", but got \"%s\"", i+1, expect[i], line)
+		}
+	}
+}
+
+func TestBackoff_Retry_Hooks(t *testing.T) {
+	t.Run("Gives up", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, 5*time.Second)
+
+		var (
+			attempts  []uint64
+			waits     []time.Duration
+			gaveUp    uint64
+			gaveUpErr error
+		)
+		b.OnAttempt = func(attempt uint64) {
+			attempts = append(attempts, attempt)
+		}
+		b.OnWait = func(d time.Duration) {
+			waits = append(waits, d)
+		}
+		b.OnGiveUp = func(attempts uint64, err error) {
+			gaveUp = attempts
+			gaveUpErr = err
+		}
+
+		fn, _ := failN(5)
+		err := b.Retry(context.Background(), fn)
+
+		if len(attempts) != 3 || attempts[0] != 1 || attempts[1] != 2 || attempts[2] != 3 {
+			t.Errorf("expected OnAttempt to be called with [1 2 3], but got %v", attempts)
+		}
+		if len(waits) != 2 || waits[0] != 2*time.Second || waits[1] != 4*time.Second {
+			t.Errorf("expected OnWait to be called with [2s 4s], but got %v", waits)
+		}
+		if gaveUp != 3 {
+			t.Errorf("expected OnGiveUp to be called with \"%d\" attempts, but got \"%d\"", 3, gaveUp)
+		}
+		if gaveUpErr != err {
+			t.Errorf("expected OnGiveUp to be called with \"%v\", but got \"%v\"", err, gaveUpErr)
+		}
+	})
+
+	t.Run("Succeeds", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, 5*time.Second)
+		b.OnGiveUp = func(uint64, error) {
+			t.Error("expected OnGiveUp to not be called")
+		}
+
+		fn, _ := failN(1)
+		if err := b.Retry(context.Background(), fn); err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+	})
+}
+
+func TestBackoff_Retry_SubtractWork(t *testing.T) {
+	clock := newMockClock()
+	timer := &mockTimer{}
+	b := backoff.New(4, 1, 1*time.Second, 1*time.Second)
+	b.Timer = timer
+	b.Clock = clock
+	b.SubtractWork = true
+	b.RecordHistory = true
+
+	// Every call takes longer than the last, the last one longer than the
+	// delay.
+	works := []time.Duration{200 * time.Millisecond, 700 * time.Millisecond, 3 * time.Second, 0}
+	var calls int
+	err := b.Retry(context.Background(), func() error {
+		clock.Advance(works[calls])
+		calls++
+		return errRetry
+	})
+	if !errors.Is(err, backoff.ErrMaxAttempts) {
+		t.Errorf("expected error to be \"%v\", but got \"%v\"", backoff.ErrMaxAttempts, err)
+	}
+
+	// A delay of 0 bypasses the timer.
+	if d := timer.durations; len(d) != 2 || d[0] != 800*time.Millisecond || d[1] != 300*time.Millisecond {
+		t.Errorf("expected the timer to be started with [800ms 300ms], but got %v", d)
+	}
+	if h := b.History(); len(h) != 4 || h[3] != 0 {
+		t.Errorf("expected the last delay to be 0, but got %v", h)
+	}
+}
+
+func TestRetryFunc(t *testing.T) {
+	b := backoff.New(3, 2, time.Millisecond, time.Second)
+	b.DryRun = true
+
+	fns := make([]func() error, 4)
+	for i := range fns {
+		fn, _ := failN(i)
+		fns[i] = backoff.RetryFunc(context.Background(), b, fn)
+	}
+
+	errs := make(chan error, len(fns))
+	for _, fn := range fns {
+		go func() { errs <- fn() }()
+	}
+	var failed int
+	for range fns {
+		if err := <-errs; err != nil {
+			if !errors.Is(err, backoff.ErrMaxAttempts) {
+				t.Errorf("expected error to be \"%v\", but got \"%v\"", backoff.ErrMaxAttempts, err)
+			}
+			failed++
+		}
+	}
+	// Only the function failing 3 times exhausts its attempts.
+	if failed != 1 {
+		t.Errorf("expected \"%d\" function to fail, but got \"%d\"", 1, failed)
+	}
+	if b.Attempt() != 0 {
+		t.Errorf("expected b to not be used, but its attempt is \"%d\"", b.Attempt())
+	}
+}
+
+func TestBackoff_MustRetry(t *testing.T) {
+	t.Run("Returns once fn succeeds", func(t *testing.T) {
+		b := newBackoffWithMockTimer(5, 0, 0, 0)
+
+		fn, calls := failN(2)
+		b.MustRetry(context.Background(), fn)
+		if *calls != 3 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 3, *calls)
+		}
+	})
+
+	t.Run("Panics once the backoff gives up", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 0, 0, 0)
+
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.Is(err, backoff.ErrMaxAttempts) || !errors.Is(err, errRetry) {
+				t.Errorf("expected a panic wrapping \"%v\", but got \"%v\"", errRetry, err)
+			}
+		}()
+		fn, _ := failN(5)
+		b.MustRetry(context.Background(), fn)
+	})
+}
+
+func TestBackoff_RetryCtx(t *testing.T) {
+	b := newBackoffWithMockTimer(3, 0, 0, 0)
+
+	var attempts []uint64
+	err := b.RetryCtx(context.Background(), func(ctx context.Context) error {
+		attempt, ok := backoff.AttemptFromContext(ctx)
+		if !ok {
+			t.Error("expected context to carry the attempt")
+		}
+		attempts = append(attempts, attempt)
+		return errRetry
+	})
+	if !errors.Is(err, errRetry) {
+		t.Errorf("expected error to be \"%v\", but got \"%v\"", errRetry, err)
+	}
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[1] != 2 || attempts[2] != 3 {
+		t.Errorf("expected attempts to be [1 2 3], but got %v", attempts)
+	}
+}
+
+func TestBackoff_RetryCtx_AttemptTimeout(t *testing.T) {
+	b := newBackoffWithMockTimer(2, 0, 0, 0)
+	b.AttemptTimeout = time.Millisecond
+
+	var calls int
+	err := b.RetryCtx(context.Background(), func(ctx context.Context) error {
+		calls++
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, backoff.ErrMaxAttempts) {
+		t.Errorf("expected error to wrap \"%v\", but got \"%v\"", context.DeadlineExceeded, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 2, calls)
+	}
+}
+
+func TestRetryResult(t *testing.T) {
+	errFatal := errors.New("fatal")
+	retryable := func(err error) bool { return !errors.Is(err, errFatal) }
+
+	t.Run("Returns the value once fn succeeds", func(t *testing.T) {
+		b := newBackoffWithMockTimer(5, 0, 0, 0)
+
+		var calls int
+		v, err := backoff.RetryResult(context.Background(), b, func(ctx context.Context) (string, error) {
+			calls++
+			if attempt, _ := backoff.AttemptFromContext(ctx); attempt < 3 {
+				return "partial", errRetry
+			}
+			return "done", nil
+		}, retryable)
+		if err != nil {
+			t.Errorf("expected no error, but got \"%v\"", err)
+		}
+		if v != "done" {
+			t.Errorf("expected value to be \"%s\", but got \"%s\"", "done", v)
+		}
+		if calls != 3 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+	})
+
+	t.Run("Stops on an error that is not retryable", func(t *testing.T) {
+		b := newBackoffWithMockTimer(5, 0, 0, 0)
+
+		var calls int
+		v, err := backoff.RetryResult(context.Background(), b, func(context.Context) (int, error) {
+			calls++
+			return 1, errFatal
+		}, retryable)
+		if err != errFatal {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errFatal, err)
+		}
+		if v != 0 {
+			t.Errorf("expected the zero value, but got \"%d\"", v)
+		}
+		if calls != 1 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 1, calls)
+		}
+	})
+
+	t.Run("Stops on a permanent error", func(t *testing.T) {
+		b := newBackoffWithMockTimer(5, 0, 0, 0)
+
+		_, err := backoff.RetryResult(context.Background(), b, func(context.Context) (int, error) {
+			return 0, backoff.Permanent(errRetry)
+		}, nil)
+		if err != errRetry {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errRetry, err)
+		}
+	})
+
+	t.Run("Retries every error without a predicate", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 0, 0, 0)
+
+		var calls int
+		_, err := backoff.RetryResult(context.Background(), b, func(context.Context) (int, error) {
+			calls++
+			return 0, errFatal
+		}, nil)
+		if !errors.Is(err, backoff.ErrMaxAttempts) || !errors.Is(err, errFatal) {
+			t.Errorf("expected error to wrap \"%v\", but got \"%v\"", errFatal, err)
+		}
+		if calls != 3 {
+			t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 3, calls)
+		}
+	})
+
+	t.Run("Returns the context cause when cancelled", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+
+		cause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		_, err := backoff.RetryResult(ctx, b, func(ctx context.Context) (int, error) {
+			cancel(cause)
+			return 0, context.Cause(ctx)
+		}, nil)
+		if !errors.Is(err, cause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", cause, err)
+		}
+		if errors.Is(err, context.Canceled) {
+			t.Errorf("expected error not to be \"%v\"", context.Canceled)
+		}
+	})
+}
+
+func TestAttemptFromContext(t *testing.T) {
+	if _, ok := backoff.AttemptFromContext(context.Background()); ok {
+		t.Error("expected AttemptFromContext to return false for a context without an attempt")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	def := backoff.Default
+	t.Cleanup(func() {
+		backoff.Default = def
+	})
+	backoff.Default = newBackoffWithMockTimer(3, 0, 0, 0)
+
+	fn, calls := failN(2)
+	if err := backoff.Retry(context.Background(), fn); err != nil {
+		t.Errorf("expected no error, but got \"%v\"", err)
+	}
+	if *calls != 3 {
+		t.Errorf("expected fn to be called \"%d\" times, but got \"%d\"", 3, *calls)
+	}
+
+	// Ensure Default's state is not modified.
+	if backoff.Default.Attempt() != 0 {
+		t.Errorf("expected Default's attempt to be \"%d\", but got \"%d\"", 0, backoff.Default.Attempt())
+	}
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"math"
+	"time"
+)
+
+// Strategy is used as an abstraction to swap out how Backoff computes the
+// delay before each attempt. The returned delay is still scaled, rounded and
+// clamped between Min and Max by the Backoff.
+type Strategy interface {
+	// Delay returns the delay before the given attempt. Delay is never called
+	// for the first attempt, so attempt is always at least 1.
+	Delay(attempt uint64) time.Duration
+}
+
+// truncatedExponential implements Strategy by doubling a base delay for every
+// failed attempt, up to a cap.
+type truncatedExponential struct {
+	base time.Duration
+	cap  time.Duration
+}
+
+var _ Strategy = truncatedExponential{}
+
+// NewTruncatedExponential returns a new Backoff that implements the "full
+// jitter" algorithm used by the AWS SDKs, where the delay before an attempt is
+// a random duration between 0 and min(cap, base * 2^attempt). If cap is 0,
+// delays are not limited.
+//
+// Unlike a Backoff returned by New with Jitter set to JitterFull, the delay
+// is not clamped to a minimum after jitter is applied, a delay may be anywhere
+// between zero and the computed delay.
+func NewTruncatedExponential(base, cap time.Duration, maxAttempts uint64) *Backoff {
+	b := New(maxAttempts, 2, 0, cap)
+	b.Jitter = JitterFull
+	b.Strategy = truncatedExponential{
+		base: base,
+		cap:  cap,
+	}
+	return b
+}
+
+func (s truncatedExponential) Delay(attempt uint64) time.Duration {
+	d := float64(s.base) * math.Pow(2, float64(attempt))
+	if s.cap > 0 && (d > float64(s.cap) || d > maxInt64) {
+		return s.cap
+	}
+	if d > maxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// polynomial implements Strategy by multiplying a base delay by the attempt
+// raised to a power.
+type polynomial struct {
+	base  time.Duration
+	power float64
+	max   time.Duration
+}
+
+var _ Strategy = polynomial{}
+
+// NewPolynomial returns a new Backoff where the delay before an attempt is
+// base * attempt^power, limited by max unless it is 0. A power of 1 results in a linear
+// backoff, while a power of 2 results in a quadratic backoff.
+func NewPolynomial(maxAttempts uint64, base time.Duration, power float64, max time.Duration) *Backoff {
+	b := New(maxAttempts, 1, base, max)
+	b.Strategy = polynomial{
+		base:  base,
+		power: power,
+		max:   max,
+	}
+	return b
+}
+
+func (s polynomial) Delay(attempt uint64) time.Duration {
+	d := float64(s.base) * math.Pow(float64(attempt), s.power)
+	if s.max > 0 && (d > float64(s.max) || d > maxInt64) {
+		return s.max
+	}
+	if d > maxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// chain implements Strategy by using the schedules of multiple Backoffs in
+// sequence.
+type chain []*Backoff
+
+var _ Strategy = chain{}
+
+// Chain returns a new Backoff that uses the schedule of every stage in order,
+// moving to the next stage once the MaxAttempts of the current stage are
+// exhausted, and gives up once every stage is exhausted. This can be used to
+// escalate from fast retries to slow ones:
+//
+//	b := backoff.Chain(
+//		backoff.New(5, 2, 100*time.Millisecond, time.Second),
+//		backoff.New(10, 1, time.Minute, time.Minute),
+//	)
+//
+// The first attempt of the first stage is delayed by its InitialDelay, every
+// other attempt is delayed by the next delay of the current stage, see
+// Backoff.DelayAt. Only the schedule of every stage is used, set Jitter on the
+// returned Backoff instead of on the stages. If a stage has no MaxAttempts,
+// the stages after it are never used. The stages are cloned, so changes made
+// to them afterwards do not affect the returned Backoff. Reset restarts the
+// returned Backoff at the first stage.
+//
+// Chain panics if no stages are given.
+func Chain(stages ...*Backoff) *Backoff {
+	if len(stages) == 0 {
+		panic("backoff: Chain requires at least one stage")
+	}
+
+	var (
+		c           = make(chain, len(stages))
+		maxAttempts uint64
+		max         time.Duration
+		unlimited   bool
+		unbounded   bool
+	)
+	for i, s := range stages {
+		c[i] = s.Clone()
+		if s.MaxAttempts == 0 {
+			unlimited = true
+		}
+		maxAttempts += s.MaxAttempts
+		if s.Max <= 0 {
+			unbounded = true
+		}
+		if s.Max > max {
+			max = s.Max
+		}
+	}
+	if unlimited {
+		maxAttempts = 0
+	}
+	// The delays of a stage without Max must not be limited by the Max of
+	// another stage.
+	if unbounded {
+		max = 0
+	}
+
+	b := New(maxAttempts, 1, 0, max)
+	b.InitialDelay = stages[0].InitialDelay
+	b.Strategy = c
+	return b
+}
+
+func (c chain) Delay(attempt uint64) time.Duration {
+	for i, s := range c {
+		if s.MaxAttempts == 0 || attempt < s.MaxAttempts {
+			if i == 0 {
+				return s.DelayAt(attempt)
+			}
+			// Every attempt of the following stages is a retry.
+			return s.DelayAt(attempt + 1)
+		}
+		attempt -= s.MaxAttempts
+	}
+	// Every stage is exhausted, which only happens if Delay is called past
+	// the MaxAttempts of the Backoff returned by Chain.
+	return c[len(c)-1].DelayAt(c[len(c)-1].MaxAttempts)
+}
+
+// sequence implements Strategy by reading the delays from a slice.
+type sequence []time.Duration
+
+var _ Strategy = sequence{}
+
+// NewSequence returns a new Backoff that waits for delays[k-1] before attempt
+// k, which can be used to replay a known schedule or to match a schedule
+// dictated by a server exactly. The first attempt is not delayed, like with
+// every other Backoff.
+//
+// Once every delay is used, the returned Backoff keeps waiting for the last
+// delay if holdLast is true, otherwise it gives up, as MaxAttempts is set to
+// one more than the number of delays. The delays are copied, so changes made
+// to the slice afterwards do not affect the returned Backoff.
+//
+// NewSequence panics if no delays are given.
+func NewSequence(delays []time.Duration, holdLast bool) *Backoff {
+	if len(delays) == 0 {
+		panic("backoff: NewSequence requires at least one delay")
+	}
+
+	s := make(sequence, len(delays))
+	copy(s, delays)
+
+	var max time.Duration
+	for _, d := range s {
+		if d > max {
+			max = d
+		}
+	}
+
+	var maxAttempts uint64
+	if !holdLast {
+		maxAttempts = uint64(len(s)) + 1
+	}
+	b := New(maxAttempts, 1, 0, max)
+	b.Strategy = s
+	return b
+}
+
+func (s sequence) Delay(attempt uint64) time.Duration {
+	if attempt > uint64(len(s)) {
+		return s[len(s)-1]
+	}
+	return s[attempt-1]
+}
@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/backoff"
+)
+
+type constantStrategy time.Duration
+
+func (s constantStrategy) Delay(uint64) time.Duration {
+	return time.Duration(s)
+}
+
+func TestBackoff_Strategy(t *testing.T) {
+	b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+	b.Strategy = constantStrategy(3 * time.Second)
+
+	ctx := context.Background()
+	b.Next(ctx)
+	if d, expect := b.Duration(), 3*time.Second; d != expect {
+		t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		return
+	}
+
+	// Ensure the result of the Strategy is still clamped.
+	b = newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+	b.Strategy = constantStrategy(10 * time.Second)
+	b.Next(ctx)
+	if d := b.Duration(); d != b.Max {
+		t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Max, d)
+	}
+}
+
+func TestNewTruncatedExponential(t *testing.T) {
+	const (
+		base = 100 * time.Millisecond
+		cap  = 1 * time.Second
+	)
+
+	t.Run("Upper bound", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.NewTruncatedExponential(base, cap, 6)
+		b.Timer = timer
+		// Disable jitter to observe the upper bound of each delay.
+		b.Rand = fixedRand(1)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		expect := []time.Duration{
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			1 * time.Second,
+			1 * time.Second,
+		}
+		if len(timer.durations) != len(expect) {
+			t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+		}
+		for i, d := range timer.durations {
+			if d != expect[i] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+			}
+		}
+	})
+
+	t.Run("Jitter is not clamped to base", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.NewTruncatedExponential(base, cap, 2)
+		b.Timer = timer
+		b.Rand = fixedRand(0.1)
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		if d, expect := timer.durations[0], 20*time.Millisecond; d != expect {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", expect, d)
+		}
+	})
+
+	t.Run("Does not overflow", func(t *testing.T) {
+		b := backoff.NewTruncatedExponential(base, cap, 0)
+		b.Timer = newMockTimer()
+		b.Rand = fixedRand(1)
+
+		// 2^1100 overflows a float64.
+		ctx := context.Background()
+		for i := 0; i < 1100; i++ {
+			b.Next(ctx)
+		}
+		if d := b.Duration(); d != cap {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", cap, d)
+		}
+	})
+}
+
+func TestNewPolynomial(t *testing.T) {
+	t.Run("Quadratic", func(t *testing.T) {
+		timer := &mockTimer{}
+		b := backoff.NewPolynomial(6, 100*time.Millisecond, 2, 2*time.Second)
+		b.Timer = timer
+
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		expect := []time.Duration{
+			100 * time.Millisecond,
+			400 * time.Millisecond,
+			900 * time.Millisecond,
+			1600 * time.Millisecond,
+			2 * time.Second,
+		}
+		if len(timer.durations) != len(expect) {
+			t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+		}
+		for i, d := range timer.durations {
+			if d != expect[i] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+			}
+		}
+	})
+
+	t.Run("Does not overflow", func(t *testing.T) {
+		b := backoff.NewPolynomial(0, time.Hour, 100, time.Duration(math.MaxInt64))
+		b.Timer = newMockTimer()
+
+		ctx := context.Background()
+		b.Next(ctx)
+		b.Next(ctx)
+		b.Next(ctx)
+		if d := b.Duration(); d != b.Max {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", b.Max, d)
+		}
+	})
+}
+
+func TestChain(t *testing.T) {
+	fast := backoff.New(3, 2, 100*time.Millisecond, time.Second)
+	slow := backoff.New(2, 1, time.Minute, time.Minute)
+
+	timer := &mockTimer{}
+	b := backoff.Chain(fast, slow)
+	b.Timer = timer
+
+	// Changes to the stages must not affect the chain.
+	fast.Min = time.Hour
+
+	if b.MaxAttempts != 5 {
+		t.Fatalf("expected max attempts to be \"%d\", but got \"%d\"", 5, b.MaxAttempts)
+	}
+
+	run := func() {
+		timer.durations = nil
+		ctx := context.Background()
+		for b.Next(ctx) {
+		}
+
+		expect := []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, time.Minute, time.Minute}
+		if len(timer.durations) != len(expect) {
+			t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(timer.durations))
+		}
+		for i, d := range timer.durations {
+			if d != expect[i] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+			}
+		}
+	}
+
+	run()
+	// Reset restarts at the first stage.
+	b.Reset()
+	run()
+
+	t.Run("Unlimited stage", func(t *testing.T) {
+		b := backoff.Chain(backoff.New(2, 2, time.Second, time.Second), backoff.New(0, 1, time.Minute, time.Minute))
+		if b.MaxAttempts != 0 {
+			t.Errorf("expected max attempts to be \"%d\", but got \"%d\"", 0, b.MaxAttempts)
+		}
+		b.NextN(100)
+		if d := b.Duration(); d != time.Minute {
+			t.Errorf("expected duration to be \"%s\", but got \"%s\"", time.Minute, d)
+		}
+	})
+
+	t.Run("Unbounded stage", func(t *testing.T) {
+		b := backoff.Chain(backoff.New(5, 10, time.Second, 0), backoff.New(3, 1, 2*time.Second, 2*time.Second))
+		b.Timer = &mockTimer{}
+		if b.Max != 0 {
+			t.Errorf("expected max to be \"%d\", but got \"%d\"", 0, b.Max)
+		}
+
+		expect := []time.Duration{0, 10 * time.Second, 100 * time.Second, 1000 * time.Second, 10000 * time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second}
+		for i, e := range expect {
+			if d := b.DelayAt(uint64(i)); d != e {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, e, d)
+			}
+		}
+	})
+
+	t.Run("Panics without stages", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected Chain to panic without stages")
+			}
+		}()
+		backoff.Chain()
+	})
+}
+
+func TestNewSequence(t *testing.T) {
+	delays := []time.Duration{time.Second, 5 * time.Second, 2 * time.Second}
+
+	run := func(t *testing.T, b *backoff.Backoff, attempts int) []time.Duration {
+		t.Helper()
+		timer := &mockTimer{}
+		b.Timer = timer
+		ctx := context.Background()
+		for i := 0; i < attempts && b.Next(ctx); i++ {
+		}
+		return timer.durations
+	}
+
+	t.Run("Stops", func(t *testing.T) {
+		b := backoff.NewSequence(delays, false)
+		// Changes to the delays must not affect the Backoff.
+		delays[0] = time.Hour
+		defer func() { delays[0] = time.Second }()
+
+		if b.MaxAttempts != 4 {
+			t.Fatalf("expected max attempts to be \"%d\", but got \"%d\"", 4, b.MaxAttempts)
+		}
+		durations := run(t, b, 10)
+		expect := []time.Duration{time.Second, 5 * time.Second, 2 * time.Second}
+		if len(durations) != len(expect) {
+			t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(durations))
+		}
+		for i, d := range durations {
+			if d != expect[i] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+			}
+		}
+	})
+
+	t.Run("Holds last", func(t *testing.T) {
+		b := backoff.NewSequence(delays, true)
+		if b.MaxAttempts != 0 {
+			t.Fatalf("expected max attempts to be \"%d\", but got \"%d\"", 0, b.MaxAttempts)
+		}
+		durations := run(t, b, 6)
+		expect := []time.Duration{time.Second, 5 * time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second}
+		if len(durations) != len(expect) {
+			t.Fatalf("expected timer to be started \"%d\" times, but got \"%d\"", len(expect), len(durations))
+		}
+		for i, d := range durations {
+			if d != expect[i] {
+				t.Errorf("Test #%d: expected duration to be \"%s\", but got \"%s\"", i+1, expect[i], d)
+			}
+		}
+	})
+
+	t.Run("Panics without delays", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected NewSequence to panic without delays")
+			}
+		}()
+		backoff.NewSequence(nil, false)
+	})
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"context"
+	"time"
+)
+
+// Ticks returns a channel that receives the current attempt every time the
+// backoff's delay has passed, the same value as Attempt returns inside of a
+// Next loop. The channel is closed once the MaxAttempts limit is reached or
+// the context is cancelled.
+//
+// Ticks calls Next in a new goroutine, which exits once the channel is closed.
+// The context must be cancelled if the channel is no longer being received
+// from, otherwise the goroutine will be leaked. The Backoff must not be used
+// until the channel is closed.
+//
+// This function was designed to be used as follows:
+//
+//	ticks := b.Ticks(ctx)
+//	for {
+//		select {
+//		case attempt, ok := <-ticks:
+//			if !ok {
+//				return
+//			}
+//			// Do work.
+//		case v := <-other:
+//			// Handle other events.
+//		}
+//	}
+func (b *Backoff) Ticks(ctx context.Context) <-chan uint64 {
+	ch := make(chan uint64)
+	go func() {
+		defer close(ch)
+		for b.Next(ctx) {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- b.n:
+			}
+		}
+	}()
+	return ch
+}
+
+// WaitChan advances the attempt like Next, then returns a channel that
+// receives once the attempt's delay has passed, so the backoff can be used as
+// part of a select statement alongside other channels. The channel is closed
+// after the value is sent. If a limit was reached or the context is cancelled
+// before the delay has passed, the channel is closed without receiving.
+//
+// The Backoff must not be used until the channel is closed. Once the delay has
+// passed the channel is closed even if it is not being received from.
+//
+//	select {
+//	case _, ok := <-b.WaitChan(ctx):
+//		if !ok {
+//			return
+//		}
+//		// Do work.
+//	case v := <-other:
+//		// Handle other events.
+//	}
+func (b *Backoff) WaitChan(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	d, err := b.advance(ctx)
+	if err != nil {
+		close(ch)
+		return ch
+	}
+	go func() {
+		defer close(ch)
+		if b.wait(ctx, d) {
+			ch <- struct{}{}
+		}
+	}()
+	return ch
+}
+
+// Range returns an iterator that calls Next for every step, yielding the
+// number of remaining attempts, see RemainingAttempts, and the delay that was
+// waited before the attempt. The iteration stops once Next returns false or
+// the loop is broken out of, the Backoff must not be used while iterating.
+//
+// The returned function is an iter.Seq2[uint64, time.Duration], which can be
+// ranged over with Go 1.23 or later:
+//
+//	for remaining, delay := range b.Range(ctx) {
+//		fmt.Printf("waited %s, %d attempts left\n", delay, remaining)
+//		// Do work, `continue` on soft-failure, `break` on success or non-retryable error.
+//	}
+func (b *Backoff) Range(ctx context.Context) func(yield func(remaining uint64, delay time.Duration) bool) {
+	return func(yield func(uint64, time.Duration) bool) {
+		for b.Next(ctx) {
+			if !yield(b.RemainingAttempts(), b.lastDelay) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitForGoroutines waits for the number of goroutines to drop to n.
+func waitForGoroutines(t *testing.T, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Errorf("expected \"%d\" goroutines, but got \"%d\"", n, runtime.NumGoroutine())
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBackoff_Ticks(t *testing.T) {
+	t.Run("Emits every attempt", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, 5*time.Second)
+
+		var expect uint64 = 1
+		for attempt := range b.Ticks(context.Background()) {
+			if attempt != expect {
+				t.Errorf("expected attempt to be \"%d\", but got \"%d\"", expect, attempt)
+				return
+			}
+			expect++
+		}
+
+		if expect != 4 {
+			t.Errorf("expected \"%d\" ticks, but got \"%d\"", 3, expect-1)
+		}
+	})
+
+	t.Run("Does not leak when cancelled", func(t *testing.T) {
+		n := runtime.NumGoroutine()
+
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		ticks := b.Ticks(ctx)
+		<-ticks
+		cancel()
+
+		// Ensure the channel gets closed.
+		for range ticks {
+		}
+
+		waitForGoroutines(t, n)
+	})
+
+	t.Run("Does not leak when cancelled without receiving", func(t *testing.T) {
+		n := runtime.NumGoroutine()
+
+		b := newBackoffWithMockTimer(0, 0, 0, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = b.Ticks(ctx)
+		cancel()
+
+		waitForGoroutines(t, n)
+	})
+}
+
+func TestBackoff_WaitChan(t *testing.T) {
+	t.Run("Receives once the delay has passed", func(t *testing.T) {
+		b := newBackoffWithMockTimer(2, 2, 1*time.Second, 5*time.Second)
+
+		for i := 0; i < 2; i++ {
+			if _, ok := <-b.WaitChan(context.Background()); !ok {
+				t.Errorf("Test #%d: expected a value to be received", i+1)
+			}
+		}
+		if _, ok := <-b.WaitChan(context.Background()); ok {
+			t.Error("expected the channel to be closed once MaxAttempts is reached")
+		}
+		if b.Attempt() != 2 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+		}
+	})
+
+	t.Run("Closes when cancelled", func(t *testing.T) {
+		n := runtime.NumGoroutine()
+
+		b := newBackoffWithMockTimer(0, 2, time.Hour, time.Hour)
+		b.InitialDelay = time.Hour
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := b.WaitChan(ctx)
+		cancel()
+
+		if _, ok := <-ch; ok {
+			t.Error("expected the channel to be closed without receiving")
+		}
+		waitForGoroutines(t, n)
+	})
+}
+
+func TestBackoff_Range(t *testing.T) {
+	t.Run("Yields every attempt", func(t *testing.T) {
+		b := newBackoffWithMockTimer(3, 2, 1*time.Second, 5*time.Second)
+
+		var (
+			remaining []uint64
+			delays    []time.Duration
+		)
+		b.Range(context.Background())(func(r uint64, d time.Duration) bool {
+			remaining = append(remaining, r)
+			delays = append(delays, d)
+			return true
+		})
+
+		if len(remaining) != 3 || remaining[0] != 2 || remaining[1] != 1 || remaining[2] != 0 {
+			t.Errorf("expected remaining attempts to be [2 1 0], but got %v", remaining)
+		}
+		if len(delays) != 3 || delays[0] != 0 || delays[1] != 2*time.Second || delays[2] != 4*time.Second {
+			t.Errorf("expected delays to be [0s 2s 4s], but got %v", delays)
+		}
+	})
+
+	t.Run("Stops when the loop is broken out of", func(t *testing.T) {
+		b := newBackoffWithMockTimer(0, 2, 1*time.Second, 5*time.Second)
+
+		var steps int
+		b.Range(context.Background())(func(uint64, time.Duration) bool {
+			steps++
+			return steps < 2
+		})
+		if steps != 2 {
+			t.Errorf("expected \"%d\" steps, but got \"%d\"", 2, steps)
+		}
+		if b.Attempt() != 2 {
+			t.Errorf("expected attempt to be \"%d\", but got \"%d\"", 2, b.Attempt())
+		}
+	})
+}
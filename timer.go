@@ -30,8 +30,13 @@ type Timer interface {
 	Start(time.Duration)
 
 	// Stop prevents the Timer from firing.
-	// It returns true if the call stops the timer, false if the timer has
-	// already expired or been stopped.
+	// It returns true if the call stops the timer, false if a value has been
+	// or will be sent on the channel returned by C and has not been received.
+	// If Stop returns false, implementations must guarantee that exactly one
+	// value will be received from the channel, otherwise callers draining
+	// the channel will block forever. Implementations that send values from
+	// another goroutine should stop that goroutine from sending and return
+	// true instead, to avoid leaking it.
 	// Stop does not close the channel, to prevent a read from the channel
 	// succeeding incorrectly.
 	//
@@ -72,12 +77,139 @@ func (t *realTimer) Start(d time.Duration) {
 		t.timer = time.NewTimer(d)
 		return
 	}
+
+	// If the timer fired without its value being received, the value would be
+	// received immediately after resetting the timer, depending on the version
+	// of Go and the GODEBUG asynctimerchan setting. Stop and drain it first, as
+	// documented by time.Timer.Reset.
+	t.Stop()
 	t.timer.Reset(d)
 }
 
 func (t *realTimer) Stop() bool {
-	if t.timer == nil {
+	if t.timer == nil || t.timer.Stop() {
 		return true
 	}
-	return t.timer.Stop()
+
+	// The timer has already fired. Depending on the version of Go and the
+	// GODEBUG asynctimerchan setting, the value is either buffered in the
+	// channel or has been discarded by Stop. Drain the channel without
+	// blocking so callers never have to, which satisfies the Timer contract
+	// in both cases.
+	select {
+	case <-t.timer.C:
+	default:
+	}
+	return true
+}
+
+// tickerTimer implements the Timer interface by wrapping a time#Ticker.
+type tickerTimer struct {
+	ticker *time.Ticker
+	// period is the period the ticker is running at, or 0 if it is stopped.
+	period time.Duration
+	// now is sent to instead of starting the ticker when Start is called with
+	// a duration that is not positive, as a ticker cannot fire immediately.
+	now chan time.Time
+	// immediate is true if C returns now instead of the ticker's channel.
+	immediate bool
+}
+
+var _ Timer = (*tickerTimer)(nil)
+
+// NewTickerTimer returns a new Timer backed by a time.Ticker. Calling Start
+// with the same duration the ticker is already running at does not reset it,
+// so it keeps firing on a fixed period measured from the first call to Start
+// instead of from every call. Starting it with any other duration, or after
+// Stop, resets the ticker to the new period.
+//
+// With a constant schedule, like a Backoff returned by NewConstant without
+// Jitter, this keeps the attempts aligned to a fixed cadence regardless of how
+// long every attempt takes, so a long-running poller does not drift. If an
+// attempt takes longer than the period, the next wait ends immediately and
+// the missed ticks are dropped, like with time.Ticker. A regular Timer, see
+// NewRealTimer, waits for the full delay after every attempt instead, which
+// is preferable for any schedule that changes between attempts, where the
+// ticker would be reset every time anyway.
+//
+// Unlike a time.Timer, the ticker keeps running after it fires, and before Go
+// 1.23 a running ticker is never garbage collected. A Backoff stops it when it
+// gives up, when a wait ends without the delay passing and when Retry, Poll
+// or Transport return. When calling Next directly, stop the Timer once the
+// loop ends because the work succeeded:
+//
+//	b.Timer = backoff.NewTickerTimer()
+//	defer b.Timer.Stop()
+//	for b.Next(ctx) {
+//		// Do work, `continue` on soft-failure, `break` on success.
+//	}
+//
+// Starting it with a duration that is not positive fires immediately without
+// starting the ticker, like a time.Timer. Like a Timer returned by
+// NewRealTimer, it is not shared with clones, see Backoff.Clone.
+func NewTickerTimer() Timer {
+	return &tickerTimer{}
+}
+
+func (t *tickerTimer) C() <-chan time.Time {
+	if t.immediate {
+		return t.now
+	}
+	if t.ticker == nil {
+		return nil
+	}
+	return t.ticker.C
+}
+
+func (t *tickerTimer) Start(d time.Duration) {
+	if d <= 0 {
+		// Fire immediately like a time.Timer would, time.NewTicker panics
+		// unless the period is positive.
+		t.Stop()
+		if t.now == nil {
+			t.now = make(chan time.Time, 1)
+		}
+		t.now <- time.Now()
+		t.immediate = true
+		return
+	}
+
+	if t.immediate {
+		t.Stop()
+	}
+	if t.ticker == nil {
+		t.ticker = time.NewTicker(d)
+		t.period = d
+		return
+	}
+	if d == t.period {
+		return
+	}
+
+	// Discard a tick of the previous period that was not received.
+	t.Stop()
+	t.ticker.Reset(d)
+	t.period = d
+}
+
+func (t *tickerTimer) Stop() bool {
+	if t.immediate {
+		t.immediate = false
+		select {
+		case <-t.now:
+		default:
+		}
+	}
+	if t.ticker == nil {
+		return true
+	}
+	t.ticker.Stop()
+	t.period = 0
+	// The channel of a time.Ticker holds at most one tick. Drain it without
+	// blocking so callers never have to.
+	select {
+	case <-t.ticker.C:
+	default:
+	}
+	return true
 }
@@ -5,16 +5,23 @@ package backoff_test
 
 import (
 	"context"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/matthewpi/backoff"
 )
 
+// mockTimer implements backoff.Timer by firing immediately from a new
+// goroutine every time Start is called.
 type mockTimer struct {
 	started bool
-	stopped bool
 	c       chan time.Time
+	// stop stops the goroutine started by the last call to Start.
+	stop chan struct{}
+
+	// durations holds every duration Start was called with.
+	durations []time.Duration
 }
 
 var _ backoff.Timer = (*mockTimer)(nil)
@@ -22,7 +29,6 @@ var _ backoff.Timer = (*mockTimer)(nil)
 func newMockTimer() backoff.Timer {
 	return &mockTimer{
 		started: false,
-		stopped: false,
 	}
 }
 
@@ -33,22 +39,65 @@ func (t *mockTimer) C() <-chan time.Time {
 	return t.c
 }
 
-func (t *mockTimer) Start(time.Duration) {
+func (t *mockTimer) Start(d time.Duration) {
+	t.durations = append(t.durations, d)
 	if !t.started {
 		t.started = true
 		t.c = make(chan time.Time)
 	}
 
+	if t.stop != nil {
+		close(t.stop)
+	}
+	stop := make(chan struct{})
+	t.stop = stop
+
+	c := t.c
 	go func() {
-		t.c <- time.Now()
+		select {
+		case c <- time.Now():
+		case <-stop:
+		}
 	}()
 }
 
 func (t *mockTimer) Stop() bool {
-	if t.stopped {
-		return true
+	// The channel is unbuffered, so if the value was not received it never
+	// will be once the goroutine is stopped.
+	if t.stop != nil {
+		close(t.stop)
+		t.stop = nil
 	}
-	t.stopped = true
+	return true
+}
+
+// lateTimer implements backoff.Timer by always returning false from Stop and
+// sending the value after Stop has been called, like a timer that fired at
+// the same time it was stopped.
+type lateTimer struct {
+	c       chan time.Time
+	stopped chan struct{}
+}
+
+var _ backoff.Timer = (*lateTimer)(nil)
+
+func (t *lateTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *lateTimer) Start(time.Duration) {
+	t.c = make(chan time.Time)
+	t.stopped = make(chan struct{})
+
+	c, stopped := t.c, t.stopped
+	go func() {
+		<-stopped
+		c <- time.Now()
+	}()
+}
+
+func (t *lateTimer) Stop() bool {
+	close(t.stopped)
 	return false
 }
 
@@ -144,3 +193,320 @@ func TestRealTimer(t *testing.T) {
 	cancel()
 	<-done
 }
+
+func TestRealTimer_Reuse(t *testing.T) {
+	t.Run("Start discards a value that was not received", func(t *testing.T) {
+		timer := backoff.NewRealTimer()
+		timer.Start(time.Millisecond)
+		// Let the timer fire without receiving the value.
+		time.Sleep(10 * time.Millisecond)
+
+		start := time.Now()
+		timer.Start(50 * time.Millisecond)
+		if d := (<-timer.C()).Sub(start); d < 50*time.Millisecond {
+			t.Errorf("expected timer to wait at least \"%s\", but got \"%s\"", 50*time.Millisecond, d)
+		}
+	})
+
+	t.Run("Sequential sequences", func(t *testing.T) {
+		b := backoff.New(2, 2, 10*time.Millisecond, time.Second)
+
+		ctx := context.Background()
+		for i := 0; i < 2; i++ {
+			b.Reset()
+			b.Next(ctx)
+
+			start := time.Now()
+			b.Next(ctx)
+			if d := time.Since(start); d < 20*time.Millisecond {
+				t.Errorf("Test #%d: expected first wait to be at least \"%s\", but got \"%s\"", i+1, 20*time.Millisecond, d)
+			}
+			// Give a stale value a chance to be sent.
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	t.Run("Start, fire and Stop cycles", func(t *testing.T) {
+		timer := backoff.NewRealTimer()
+		for i := 0; i < 5; i++ {
+			// Fire and receive.
+			timer.Start(time.Millisecond)
+			<-timer.C()
+			// Stop after the value was received, which must not block.
+			timer.Stop()
+
+			// Fire without receiving, then Stop.
+			timer.Start(time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+			timer.Stop()
+
+			// Stop before firing.
+			timer.Start(time.Hour)
+			timer.Stop()
+
+			select {
+			case <-timer.C():
+				t.Fatalf("Test #%d: expected no value to be received after Stop", i+1)
+			default:
+			}
+		}
+	})
+
+	t.Run("Cancelled and completed waits", func(t *testing.T) {
+		b := backoff.New(0, 1, 5*time.Millisecond, 5*time.Millisecond)
+		for i := 0; i < 5; i++ {
+			// A completed wait.
+			if !b.Next(context.Background()) {
+				t.Fatalf("Test #%d: expected Next to return true", i+1)
+			}
+
+			// A wait cancelled around the time the timer fires, so either
+			// may win.
+			ctx, cancel := context.WithCancel(context.Background())
+			stop := time.AfterFunc(5*time.Millisecond, cancel)
+			b.Next(ctx)
+			stop.Stop()
+			cancel()
+
+			start := time.Now()
+			if !b.Next(context.Background()) {
+				t.Fatalf("Test #%d: expected Next to return true", i+1)
+			}
+			if d := time.Since(start); d < 5*time.Millisecond {
+				t.Errorf("Test #%d: expected wait to be at least \"%s\", but got \"%s\"", i+1, 5*time.Millisecond, d)
+			}
+		}
+	})
+}
+
+func TestTickerTimer(t *testing.T) {
+	t.Run("Keeps its cadence", func(t *testing.T) {
+		timer := backoff.NewTickerTimer()
+		if timer.C() != nil {
+			t.Fatal("expected timer.C() to return nil when the timer has not started")
+		}
+
+		timer.Start(50 * time.Millisecond)
+		<-timer.C()
+		// Simulate an attempt taking most of the period.
+		time.Sleep(30 * time.Millisecond)
+
+		start := time.Now()
+		timer.Start(50 * time.Millisecond)
+		<-timer.C()
+		if d := time.Since(start); d > 40*time.Millisecond {
+			t.Errorf("expected the wait to be shortened by the time taken, but it took \"%s\"", d)
+		}
+	})
+
+	t.Run("Resets on a different period", func(t *testing.T) {
+		timer := backoff.NewTickerTimer()
+		timer.Start(10 * time.Millisecond)
+		<-timer.C()
+		// Let a tick of the old period be sent without receiving it.
+		time.Sleep(20 * time.Millisecond)
+
+		start := time.Now()
+		timer.Start(50 * time.Millisecond)
+		<-timer.C()
+		if d := time.Since(start); d < 50*time.Millisecond {
+			t.Errorf("expected timer to wait at least \"%s\", but got \"%s\"", 50*time.Millisecond, d)
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		timer := backoff.NewTickerTimer()
+		if !timer.Stop() {
+			t.Fatal("expected timer.Stop() to return true when the timer has not started")
+		}
+
+		timer.Start(time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		if !timer.Stop() {
+			t.Fatal("expected timer.Stop() to return true")
+		}
+		select {
+		case <-timer.C():
+			t.Error("expected no value to be received after Stop")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		// Start restarts the ticker after Stop, even with the same period.
+		timer.Start(time.Millisecond)
+		select {
+		case <-timer.C():
+		case <-time.After(time.Second):
+			t.Error("expected the timer to fire after being restarted")
+		}
+	})
+
+	t.Run("Fires immediately without a positive duration", func(t *testing.T) {
+		timer := backoff.NewTickerTimer()
+		for _, d := range []time.Duration{0, -time.Second} {
+			timer.Start(d)
+			select {
+			case <-timer.C():
+			default:
+				t.Fatalf("expected a value to be ready as soon as Start(%s) returns", d)
+			}
+			select {
+			case <-timer.C():
+				t.Fatalf("expected Start(%s) to fire only once", d)
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+
+		start := time.Now()
+		timer.Start(20 * time.Millisecond)
+		<-timer.C()
+		if d := time.Since(start); d < 20*time.Millisecond {
+			t.Errorf("expected timer to wait at least \"%s\", but got \"%s\"", 20*time.Millisecond, d)
+		}
+		timer.Stop()
+	})
+
+	// stopped fails the test if the ticker fires again.
+	stopped := func(t *testing.T, timer backoff.Timer) {
+		t.Helper()
+		select {
+		case <-timer.C():
+			t.Error("expected the ticker to be stopped")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	t.Run("Stopped when the Backoff gives up", func(t *testing.T) {
+		b := backoff.NewConstant(3, time.Millisecond)
+		b.Timer = backoff.NewTickerTimer()
+		for b.Next(context.Background()) {
+		}
+		stopped(t, b.Timer)
+	})
+
+	t.Run("Stopped when a wait reaches the deadline", func(t *testing.T) {
+		b := backoff.NewConstant(0, time.Millisecond)
+		b.Timer = backoff.NewTickerTimer()
+		b.NextN(1)
+		b.Next(context.Background())
+
+		b.Max, b.Min = time.Hour, time.Hour
+		b.NextN(1)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		if b.Next(ctx) {
+			t.Fatal("expected Next to return false")
+		}
+		stopped(t, b.Timer)
+	})
+
+	t.Run("Stopped when Retry returns", func(t *testing.T) {
+		b := backoff.NewConstant(5, time.Millisecond)
+		b.Timer = backoff.NewTickerTimer()
+		fn, _ := failN(2)
+		if err := b.Retry(context.Background(), fn); err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		stopped(t, b.Timer)
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		b := backoff.NewConstant(4, 10*time.Millisecond)
+		b.Timer = backoff.NewTickerTimer()
+		if c := b.Clone(); c.Timer == b.Timer {
+			t.Error("expected the clone to get its own Timer")
+		}
+
+		var attempts int
+		for b.Next(context.Background()) {
+			attempts++
+		}
+		if attempts != 4 {
+			t.Errorf("expected \"%d\" attempts, but got \"%d\"", 4, attempts)
+		}
+	})
+}
+
+// startedTimer wraps a backoff.Timer to signal every call to Start once it
+// returned. Until Stop is called, C returns nil, so a wait only ends once the
+// context is done, even if the wrapped Timer fires immediately.
+type startedTimer struct {
+	backoff.Timer
+	started chan struct{}
+	stopped bool
+}
+
+func (t *startedTimer) C() <-chan time.Time {
+	if !t.stopped {
+		return nil
+	}
+	return t.Timer.C()
+}
+
+func (t *startedTimer) Start(d time.Duration) {
+	t.stopped = false
+	t.Timer.Start(d)
+	t.started <- struct{}{}
+}
+
+func (t *startedTimer) Stop() bool {
+	t.stopped = true
+	return t.Timer.Stop()
+}
+
+func TestTimer_DoesNotLeak(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		timer func() backoff.Timer
+	}{
+		{
+			name:  "Real",
+			timer: backoff.NewRealTimer,
+		},
+		{
+			name:  "Ticker",
+			timer: backoff.NewTickerTimer,
+		},
+		{
+			name:  "Mock",
+			timer: newMockTimer,
+		},
+		{
+			name: "Late",
+			timer: func() backoff.Timer {
+				return &lateTimer{}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := runtime.NumGoroutine()
+
+			for i := 0; i < 10; i++ {
+				b := backoff.New(0, 2, time.Hour, time.Hour)
+				timer := &startedTimer{Timer: tc.timer(), started: make(chan struct{}, 1)}
+				b.Timer = timer
+
+				ctx, cancel := context.WithCancel(context.Background())
+				// Run the first attempt, which has no delay.
+				b.Next(ctx)
+
+				done := make(chan bool)
+				go func() {
+					done <- b.Next(ctx)
+				}()
+				// Cancel once the wait started, so the Timer has to be stopped.
+				<-timer.started
+				cancel()
+				if <-done {
+					t.Error("expected Next to return false when the context is cancelled")
+					return
+				}
+				if !timer.stopped {
+					t.Error("expected the Timer to be stopped when the context is cancelled")
+					return
+				}
+			}
+
+			waitForGoroutines(t, n)
+		})
+	}
+}
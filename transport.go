@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// Transport implements http.RoundTripper by retrying requests using a
+// Backoff. Requests are retried if the underlying RoundTripper returns an
+// error, or if RetryStatus reports that the status code of the response
+// should be retried.
+//
+// Requests with a body are only retried if the body can be rewound using
+// http.Request.GetBody, which is set by http.NewRequest for common body
+// types, or if the body was buffered because it is not larger than
+// MaxBufferedBody. Other requests are sent once and their response or error
+// is returned as is, as retrying them would send an empty or partial body.
+type Transport struct {
+	// Base is the RoundTripper used to send requests. If nil,
+	// http.DefaultTransport is used.
+	Base http.RoundTripper
+	// Backoff is cloned for every request, so the Transport can be used
+	// concurrently. If nil, Default is used.
+	Backoff *Backoff
+	// RetryStatus reports whether a response with the given status code
+	// should be retried. Responses are returned immediately if it returns
+	// false. If nil, DefaultRetryStatus is used.
+	RetryStatus func(code int) bool
+	// MaxBufferedBody is the max size in bytes of a request body that is
+	// buffered in memory so it can be sent again, if the request does not
+	// have GetBody set. If set to 0, bodies are never buffered.
+	MaxBufferedBody int64
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// DefaultRetryStatus reports whether the given status code is commonly used
+// for errors that should be retried, which are 429 Too Many Requests, 500
+// Internal Server Error, 502 Bad Gateway, 503 Service Unavailable and 504
+// Gateway Timeout.
+func DefaultRetryStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// RoundTrip sends the request, retrying it as described by Transport. Once
+// the backoff gives up, the last response or error is returned. If the
+// request's context is cancelled while waiting to retry, the cause of its
+// cancellation is returned.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	retryStatus := t.RetryStatus
+	if retryStatus == nil {
+		retryStatus = DefaultRetryStatus
+	}
+	b := t.Backoff
+	if b == nil {
+		b = Default
+	}
+	b = b.Clone()
+	defer b.releaseTimer()
+
+	req, err := t.buffer(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+	var resp *http.Response
+	for b.Next(ctx) {
+		r := req
+		if resp != nil || err != nil {
+			// A retry is about to be sent, so the previous response is no
+			// longer needed.
+			if resp != nil {
+				drain(resp)
+			}
+			r, err = rewind(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err = base.RoundTrip(r)
+		if err == nil && !retryStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if !rewindable(req) {
+			break
+		}
+	}
+
+	if cerr := context.Cause(ctx); cerr != nil {
+		if resp != nil {
+			drain(resp)
+		}
+		if resp == nil && err == nil && req.Body != nil {
+			// The request was never sent, but RoundTrip must always close the
+			// body.
+			_ = req.Body.Close()
+		}
+		return nil, cerr
+	}
+	return resp, err
+}
+
+// buffer reads the body of the request into memory if it cannot be rewound
+// and is not larger than MaxBufferedBody, returning a copy of the request
+// with GetBody set. Otherwise, the request is returned as is, with the body
+// restored if it was partially read.
+func (t *Transport) buffer(req *http.Request) (*http.Request, error) {
+	if t.MaxBufferedBody <= 0 || rewindable(req) {
+		return req, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(req.Body, t.MaxBufferedBody+1))
+	if err != nil {
+		_ = req.Body.Close()
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	if int64(len(buf)) > t.MaxBufferedBody {
+		// The body is too large, send the part that was already read followed
+		// by the rest of it without retrying.
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), req.Body), req.Body}
+		return r, nil
+	}
+
+	_ = req.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(buf))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+	return r, nil
+}
+
+// rewindable reports whether the body of the request can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of the request with a new body, so it can be sent
+// again.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = body
+	return r, nil
+}
+
+// drain reads the rest of the response's body and closes it, so the
+// connection can be re-used.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2024 Matthew Penner
+
+package backoff_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matthewpi/backoff"
+)
+
+// statusServer returns a server that responds with the given status codes in
+// order, followed by 200 OK.
+func statusServer(t *testing.T, codes ...int) (srv *httptest.Server, requests *int) {
+	requests = new(int)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		*requests++
+		if *requests <= len(codes) {
+			w.WriteHeader(codes[*requests-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+// roundTripFunc implements http.RoundTripper using a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	for i, tc := range []struct {
+		name        string
+		codes       []int
+		retryStatus func(int) bool
+		expect      int
+		requests    int
+	}{
+		{
+			name:     "Retries default statuses",
+			codes:    []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+			expect:   http.StatusOK,
+			requests: 3,
+		},
+		{
+			name:     "Returns other statuses immediately",
+			codes:    []int{http.StatusConflict},
+			expect:   http.StatusConflict,
+			requests: 1,
+		},
+		{
+			name:  "Uses RetryStatus",
+			codes: []int{http.StatusConflict, http.StatusTooEarly},
+			retryStatus: func(code int) bool {
+				return code == http.StatusConflict || code == http.StatusTooEarly
+			},
+			expect:   http.StatusOK,
+			requests: 3,
+		},
+		{
+			name:     "Returns the last response once the backoff gives up",
+			codes:    []int{500, 502, 504, 500},
+			expect:   http.StatusGatewayTimeout,
+			requests: 3,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, requests := statusServer(t, tc.codes...)
+			b, _ := backoff.NewForTest()
+			b.MaxAttempts = 3
+
+			client := &http.Client{Transport: &backoff.Transport{Backoff: b, RetryStatus: tc.retryStatus}}
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Fatalf("Test #%d: expected no error, but got \"%v\"", i+1, err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.expect {
+				t.Errorf("Test #%d: expected status to be \"%d\", but got \"%d\"", i+1, tc.expect, resp.StatusCode)
+			}
+			if *requests != tc.requests {
+				t.Errorf("Test #%d: expected \"%d\" requests, but got \"%d\"", i+1, tc.requests, *requests)
+			}
+		})
+	}
+
+	t.Run("Retries transport errors", func(t *testing.T) {
+		srv, requests := statusServer(t)
+		errTransport := errors.New("connection reset")
+
+		var calls int
+		base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls <= 2 {
+				return nil, errTransport
+			}
+			return http.DefaultTransport.RoundTrip(req)
+		})
+
+		b, _ := backoff.NewForTest()
+		client := &http.Client{Transport: &backoff.Transport{Base: base, Backoff: b}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected no error, but got \"%v\"", err)
+		}
+		resp.Body.Close()
+
+		if calls != 3 || *requests != 1 {
+			t.Errorf("expected \"%d\" calls and \"%d\" request, but got \"%d\" and \"%d\"", 3, 1, calls, *requests)
+		}
+	})
+
+	t.Run("Returns the context error when cancelled", func(t *testing.T) {
+		srv, _ := statusServer(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, _ := backoff.NewForTest()
+		transport := &backoff.Transport{Backoff: b}
+		if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", context.Canceled, err)
+		}
+	})
+
+	t.Run("Returns the cause when cancelled", func(t *testing.T) {
+		srv, _ := statusServer(t)
+
+		errCause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errCause)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, _ := backoff.NewForTest()
+		transport := &backoff.Transport{Backoff: b}
+		if _, err := transport.RoundTrip(req); !errors.Is(err, errCause) {
+			t.Errorf("expected error to be \"%v\", but got \"%v\"", errCause, err)
+		}
+	})
+}
+
+func TestTransport_Body(t *testing.T) {
+	const body = "hello, world!"
+
+	// bodyServer returns a server that responds with 503 Service Unavailable
+	// to the first request, recording the body of every request.
+	bodyServer := func(t *testing.T) (srv *httptest.Server, bodies *[]string) {
+		bodies = new([]string)
+		srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			*bodies = append(*bodies, string(b))
+			if len(*bodies) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return srv, bodies
+	}
+
+	// pipeBody returns a body that cannot be rewound.
+	pipeBody := func(s string) io.Reader {
+		pr, pw := io.Pipe()
+		go func() {
+			_, _ = io.WriteString(pw, s)
+			_ = pw.Close()
+		}()
+		return pr
+	}
+
+	for i, tc := range []struct {
+		name            string
+		body            func() io.Reader
+		maxBufferedBody int64
+		expect          []string
+		status          int
+	}{
+		{
+			name:   "Rewinds bodies with GetBody",
+			body:   func() io.Reader { return bytes.NewReader([]byte(body)) },
+			expect: []string{body, body},
+			status: http.StatusOK,
+		},
+		{
+			name:   "Does not retry bodies without GetBody",
+			body:   func() io.Reader { return pipeBody(body) },
+			expect: []string{body},
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name:            "Buffers small bodies",
+			body:            func() io.Reader { return pipeBody(body) },
+			maxBufferedBody: int64(len(body)),
+			expect:          []string{body, body},
+			status:          http.StatusOK,
+		},
+		{
+			name:            "Does not retry bodies larger than MaxBufferedBody",
+			body:            func() io.Reader { return pipeBody(body) },
+			maxBufferedBody: 4,
+			expect:          []string{body},
+			status:          http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, bodies := bodyServer(t)
+			req, err := http.NewRequest(http.MethodPost, srv.URL, tc.body())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, _ := backoff.NewForTest()
+			transport := &backoff.Transport{Backoff: b, MaxBufferedBody: tc.maxBufferedBody}
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("Test #%d: expected no error, but got \"%v\"", i+1, err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Errorf("Test #%d: expected status to be \"%d\", but got \"%d\"", i+1, tc.status, resp.StatusCode)
+			}
+			if len(*bodies) != len(tc.expect) {
+				t.Fatalf("Test #%d: expected \"%d\" requests, but got \"%d\"", i+1, len(tc.expect), len(*bodies))
+			}
+			for j, b := range *bodies {
+				if b != tc.expect[j] {
+					t.Errorf("Test #%d: expected body to be \"%s\", but got \"%s\"", i+1, tc.expect[j], b)
+				}
+			}
+		})
+	}
+}